@@ -0,0 +1,60 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// BaggageAttributes returns the members of the baggage in keys as span attributes
+func BaggageAttributes(bags baggage.Baggage, keys []string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, key := range keys {
+		if member := bags.Member(key); member.Key() != "" {
+			attrs = append(attrs, attribute.String(key, member.Value()))
+		}
+	}
+	return attrs
+}
+
+// BaggageLabels returns the members of the baggage in keys as metric labels, every key is labelled
+// so that the label set stays the same, the missing members are labelled unknown
+func BaggageLabels(bags baggage.Baggage, keys []string) []label.CwLabel {
+	labels := make([]label.CwLabel, 0, len(keys))
+	for _, key := range keys {
+		value := semantic.UnknownLabelValue
+		if member := bags.Member(key); member.Key() != "" && member.Value() != "" {
+			value = member.Value()
+		}
+		labels = append(labels, label.CwLabel{Key: baggageLabelName(key), Value: value})
+	}
+	return labels
+}
+
+// baggageLabelName returns a valid prometheus label name for the baggage member key,
+// the other characters like the dot of tenant.id are replaced by underscores
+func baggageLabelName(key string) string {
+	name := []byte(key)
+	for i, c := range name {
+		valid := c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || (i > 0 && '0' <= c && c <= '9')
+		if !valid {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
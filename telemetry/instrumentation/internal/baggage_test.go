@@ -0,0 +1,38 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+)
+
+func TestBaggageLabels(t *testing.T) {
+	tenant, err := baggage.NewMember("tenant.id", "t1")
+	assert.Nil(t, err)
+	bags, err := baggage.New(tenant)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []label.CwLabel{
+		{Key: "tenant_id", Value: "t1"},
+		{Key: "canary", Value: semantic.UnknownLabelValue},
+		{Key: "_x_y", Value: semantic.UnknownLabelValue},
+	}, BaggageLabels(bags, []string{"tenant.id", "canary", "0x-y"}))
+}
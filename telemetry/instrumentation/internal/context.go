@@ -17,6 +17,8 @@ package internal
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
@@ -27,6 +29,12 @@ var traceCarrierContextKey traceCarrierContextKeyType
 type TraceCarrier struct {
 	tracer oteltrace.Tracer
 	span   oteltrace.Span
+
+	// baggage extracted by the server middlewares, the tracers finish without the handler context
+	baggage baggage.Baggage
+
+	// peer service attributes propagated by the callers, extracted by the server middlewares
+	peerService []attribute.KeyValue
 }
 
 func WithTraceCarrier(ctx context.Context, tc *TraceCarrier) context.Context {
@@ -56,3 +64,19 @@ func (t *TraceCarrier) Span() oteltrace.Span {
 func (t *TraceCarrier) SetSpan(span oteltrace.Span) {
 	t.span = span
 }
+
+func (t *TraceCarrier) Baggage() baggage.Baggage {
+	return t.baggage
+}
+
+func (t *TraceCarrier) SetBaggage(bags baggage.Baggage) {
+	t.baggage = bags
+}
+
+func (t *TraceCarrier) PeerService() []attribute.KeyValue {
+	return t.peerService
+}
+
+func (t *TraceCarrier) SetPeerService(attrs []attribute.KeyValue) {
+	t.peerService = attrs
+}
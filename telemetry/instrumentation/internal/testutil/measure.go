@@ -0,0 +1,59 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"context"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	cwmetric "github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/metric"
+)
+
+var _ cwmetric.Measure = (*RecordingMeasure)(nil)
+
+// RecordingMeasure keeps the values and the labels of every measurement by metric type,
+// the counters are recorded as the added values
+type RecordingMeasure struct {
+	Records map[string][]float64
+	Labels  map[string][][]label.CwLabel
+}
+
+func (m *RecordingMeasure) Inc(ctx context.Context, metricType string, labels ...label.CwLabel) error {
+	return m.Add(ctx, metricType, 1, labels...)
+}
+
+func (m *RecordingMeasure) Add(ctx context.Context, metricType string, value int, labels ...label.CwLabel) error {
+	return m.Record(ctx, metricType, float64(value), labels...)
+}
+
+func (m *RecordingMeasure) Record(ctx context.Context, metricType string, value float64, labels ...label.CwLabel) error {
+	if m.Records == nil {
+		m.Records = make(map[string][]float64)
+		m.Labels = make(map[string][][]label.CwLabel)
+	}
+	m.Records[metricType] = append(m.Records[metricType], value)
+	m.Labels[metricType] = append(m.Labels[metricType], labels)
+	return nil
+}
+
+// ContainsLabel reports whether l is one of labels
+func ContainsLabel(labels []label.CwLabel, l label.CwLabel) bool {
+	for _, got := range labels {
+		if got == l {
+			return true
+		}
+	}
+	return false
+}
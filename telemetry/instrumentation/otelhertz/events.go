@@ -15,6 +15,8 @@
 package otelhertz
 
 import (
+	"sort"
+
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
 	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
 	"go.opentelemetry.io/otel/attribute"
@@ -34,17 +36,49 @@ var commonEvents = map[string]stats.Event{
 	"write_finish":         stats.WriteFinish,
 }
 
-func injectStatsEventsToSpan(span trace.Span, st traceinfo.HTTPStats) {
+// StatsEventFilter reports whether a stats event is added as an event of the span, name is the name of the span event
+type StatsEventFilter func(name string, event traceinfo.Event) bool
+
+type namedEvent struct {
+	name  string
+	event traceinfo.Event
+}
+
+// injectStatsEventsToSpan adds the recorded stats events to the span in the order they happened
+func injectStatsEventsToSpan(span trace.Span, st traceinfo.HTTPStats, cfg *Config) {
+	events := make([]namedEvent, 0, len(commonEvents)+len(cfg.statsEvents))
+	collect := func(name string, event stats.Event) {
+		gotEvent := st.GetEvent(event)
+		if gotEvent == nil || (cfg.statsEventFilter != nil && !cfg.statsEventFilter(name, gotEvent)) {
+			return
+		}
+		events = append(events, namedEvent{name: name, event: gotEvent})
+	}
 	for name, event := range commonEvents {
-		if gotEvent := st.GetEvent(event); gotEvent != nil {
-			attrs := []attribute.KeyValue{attribute.Int("event.status", int(gotEvent.Status()))}
-			if gotEvent.Info() != "" {
-				attrs = append(attrs, attribute.String("event.info", gotEvent.Info()))
-			}
-			span.AddEvent(name,
-				trace.WithTimestamp(gotEvent.Time()),
-				trace.WithAttributes(attrs...),
-			)
+		if _, ok := cfg.statsEvents[name]; !ok {
+			collect(name, event)
+		}
+	}
+	for name, event := range cfg.statsEvents {
+		collect(name, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		ti, tj := events[i].event.Time(), events[j].event.Time()
+		if ti.Equal(tj) {
+			return events[i].name < events[j].name
+		}
+		return ti.Before(tj)
+	})
+
+	for _, e := range events {
+		attrs := []attribute.KeyValue{attribute.Int("event.status", int(e.event.Status()))}
+		if e.event.Info() != "" {
+			attrs = append(attrs, attribute.String("event.info", e.event.Info()))
 		}
+		span.AddEvent(e.name,
+			trace.WithTimestamp(e.event.Time()),
+			trace.WithAttributes(attrs...),
+		)
 	}
 }
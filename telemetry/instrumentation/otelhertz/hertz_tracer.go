@@ -39,6 +39,7 @@ import (
 	"github.com/cloudwego/hertz/pkg/common/tracer"
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -96,6 +97,14 @@ func (h HertzTracer) Finish(ctx context.Context, c *app.RequestContext) {
 	}
 
 	tc := internal.TraceCarrierFromContext(ctx)
+
+	// the baggage is extracted by the server middleware
+	var bags baggage.Baggage
+	if tc != nil {
+		bags = tc.Baggage()
+	}
+	labels = append(labels, internal.BaggageLabels(bags, h.cfg.baggageLabelKeys)...)
+
 	var span trace.Span
 	if tc != nil && tc.Span() != nil && tc.Span().IsRecording() {
 		span = tc.Span()
@@ -113,7 +122,7 @@ func (h HertzTracer) Finish(ctx context.Context, c *app.RequestContext) {
 		}
 		span.SetAttributes(attrs...)
 
-		injectStatsEventsToSpan(span, st)
+		injectStatsEventsToSpan(span, st, h.cfg)
 
 		if panicMsg, panicStack, httpErr := parseHTTPError(ti); httpErr != nil || len(panicMsg) > 0 {
 			recordErrorSpanWithStack(span, httpErr, panicMsg, panicStack)
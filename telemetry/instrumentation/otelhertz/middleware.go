@@ -83,7 +83,7 @@ func ClientMiddleware(opts ...Option) client.Middleware {
 			if httpReq, err := adaptor.GetCompatRequest(req); err == nil {
 				span.SetAttributes(semconv.NetAttributesFromHTTPRequest("tcp", httpReq)...)
 				span.SetAttributes(semconv.EndUserAttributesFromHTTPRequest(httpReq)...)
-				span.SetAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", cfg.clientSpanNameFormatter(req), httpReq)...)
+				span.SetAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", cfg.clientHttpRouteFormatter(req), httpReq)...)
 			}
 
 			// span attributes
@@ -110,8 +110,15 @@ func ClientMiddleware(opts ...Option) client.Middleware {
 
 			// record meter
 			labels = append(labels, label.ToCwLabelsFromOtels(metricsAttributes)...)
+			labels = append(labels, internal.BaggageLabels(baggage.FromContext(ctx), cfg.baggageLabelKeys)...)
 			cfg.measure.Inc(ctx, semantic.HTTPCounter, labels...)
 			cfg.measure.Record(ctx, semantic.HTTPLatency, float64(time.Since(start))/float64(time.Millisecond), labels...)
+			if size := requestBodySize(req); size >= 0 {
+				cfg.measure.Record(ctx, semantic.HTTPRequestSize, float64(size), labels...)
+			}
+			if size := responseBodySize(resp); err == nil && size >= 0 {
+				cfg.measure.Record(ctx, semantic.HTTPResponseSize, float64(size), labels...)
+			}
 			return
 		}
 	}
@@ -150,11 +157,13 @@ func ServerMiddleware(cfg *Config) app.HandlerFunc {
 
 		// set baggage
 		ctx = baggage.ContextWithBaggage(ctx, bags)
+		tc.SetBaggage(bags)
 
 		ctx, span := sTracer.Start(oteltrace.ContextWithRemoteSpanContext(ctx, spanCtx), cfg.serverSpanNameFormatter(c), opts...)
 
 		// peer service attributes
 		span.SetAttributes(peerServiceAttributes...)
+		span.SetAttributes(internal.BaggageAttributes(bags, cfg.baggageAttributeKeys)...)
 
 		// set span and attrs into tracer carrier for serverTracer finish
 		tc.SetSpan(span)
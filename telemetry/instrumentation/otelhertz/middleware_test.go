@@ -24,10 +24,15 @@ import (
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/test/assert"
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
+	"github.com/cloudwego/hertz/pkg/protocol"
 	"go.opentelemetry.io/otel"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/internal/testutil"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
 )
 
 func TestServerMiddleware(t *testing.T) {
@@ -68,3 +73,25 @@ func TestServerMiddlewareDisableTrace(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, len(resp.Header.Get("trace-id")) == 0)
 }
+
+func TestClientMiddlewareSizeAndRoute(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tracetest.NewSpanRecorder())))
+	measure := &testutil.RecordingMeasure{}
+	mw := ClientMiddleware(WithMeasure(measure), WithClientRoutePatterns("/users/:id"))
+
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	defer protocol.ReleaseRequest(req)
+	defer protocol.ReleaseResponse(resp)
+	req.SetRequestURI("http://127.0.0.1:6666/users/123")
+	req.SetMethod(http.MethodPost)
+	req.SetBodyString("hello")
+	err := mw(func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+		resp.SetBodyString("hello world")
+		return nil
+	})(context.Background(), req, resp)
+	assert.Nil(t, err)
+
+	assert.DeepEqual(t, []float64{5}, measure.Records[semantic.HTTPRequestSize])
+	assert.DeepEqual(t, []float64{11}, measure.Records[semantic.HTTPResponseSize])
+	assert.Assert(t, testutil.ContainsLabel(measure.Labels[semantic.HTTPCounter][0], label.CwLabel{Key: "http_route", Value: "/users/:id"}))
+}
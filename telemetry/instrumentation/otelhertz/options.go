@@ -24,6 +24,7 @@ import (
 	cwmetric "github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/metric"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
 	"github.com/cloudwego/hertz/pkg/protocol"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
@@ -66,6 +67,12 @@ type Config struct {
 	customResponseHandler app.HandlerFunc
 	shouldIgnore          ConditionFunc
 	measure               cwmetric.Measure
+
+	statsEvents      map[string]stats.Event
+	statsEventFilter StatsEventFilter
+
+	baggageAttributeKeys []string
+	baggageLabelKeys     []string
 }
 
 func NewConfig(opts ...Option) *Config {
@@ -154,6 +161,15 @@ func WithClientHttpRouteFormatter(clientHttpRouteFormatter func(req *protocol.Re
 	})
 }
 
+// WithClientRoutePatterns formats the routes of the client calls with the first matching pattern,
+// like /users/:id for /users/123, to bound the cardinality of the metric labels. The paths matching
+// no pattern are kept as is.
+func WithClientRoutePatterns(patterns ...string) Option {
+	return option(func(cfg *Config) {
+		cfg.clientHttpRouteFormatter = newRouteMatcher(patterns).formatter()
+	})
+}
+
 // WithServerHttpRouteFormatter configures serverHttpRouteFormatter
 func WithServerHttpRouteFormatter(serverHttpRouteFormatter func(c *app.RequestContext) string) Option {
 	return option(func(cfg *Config) {
@@ -194,3 +210,39 @@ func WithLabelFunc(getLabelFromRequest func(c *app.RequestContext) []label.CwLab
 		cfg.labelFunc = getLabelFromRequest
 	})
 }
+
+// WithStatsEvents adds user defined stats events to the span events, the key is the name of the span event
+func WithStatsEvents(events map[string]stats.Event) Option {
+	return option(func(cfg *Config) {
+		if cfg.statsEvents == nil {
+			cfg.statsEvents = make(map[string]stats.Event, len(events))
+		}
+		for name, event := range events {
+			cfg.statsEvents[name] = event
+		}
+	})
+}
+
+// WithStatsEventFilter configures which stats events are added to the span events
+func WithStatsEventFilter(filter StatsEventFilter) Option {
+	return option(func(cfg *Config) {
+		cfg.statsEventFilter = filter
+	})
+}
+
+// WithBaggageAttributes copies the members of the baggage in keys onto the server spans
+func WithBaggageAttributes(keys ...string) Option {
+	return option(func(cfg *Config) {
+		cfg.baggageAttributeKeys = keys
+	})
+}
+
+// WithBaggageMetricLabels adds the members of the baggage in keys to the metric labels,
+// keep the keys to low cardinality members like tenant or canary. The characters which are not
+// valid in prometheus label names are replaced by underscores, tenant.id is labelled tenant_id.
+// Declare the labels with promprovider.WithHTTPExtraLabels, the prometheus metrics are not recorded otherwise
+func WithBaggageMetricLabels(keys ...string) Option {
+	return option(func(cfg *Config) {
+		cfg.baggageLabelKeys = keys
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhertz
+
+import (
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// routeMatcher matches the request paths against the route patterns of the hertz router,
+// like /users/:id or /static/*filepath
+type routeMatcher struct {
+	patterns []string
+	segments [][]string
+}
+
+func newRouteMatcher(patterns []string) *routeMatcher {
+	m := &routeMatcher{patterns: patterns}
+	for _, pattern := range patterns {
+		m.segments = append(m.segments, splitPath(pattern))
+	}
+	return m
+}
+
+// match returns the first pattern matching path
+func (m *routeMatcher) match(path string) (string, bool) {
+	segments := splitPath(path)
+	for i, pattern := range m.segments {
+		if matchSegments(pattern, segments) {
+			return m.patterns[i], true
+		}
+	}
+	return "", false
+}
+
+// formatter returns the route formatter of the clients, the paths matching no pattern are kept as is
+func (m *routeMatcher) formatter() func(req *protocol.Request) string {
+	return func(req *protocol.Request) string {
+		path := string(req.Path())
+		if route, ok := m.match(path); ok {
+			return route
+		}
+		return path
+	}
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "*") {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if strings.HasPrefix(p, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if p != segments[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(segments)
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
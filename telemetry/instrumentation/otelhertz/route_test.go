@@ -0,0 +1,40 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhertz
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestRouteMatcher(t *testing.T) {
+	m := newRouteMatcher([]string{"/", "/users/:id", "/users/:id/orders", "/static/*filepath"})
+	for path, want := range map[string]string{
+		"/":                 "/",
+		"/users/123":        "/users/:id",
+		"/users/123/":       "/users/:id",
+		"/users/123/orders": "/users/:id/orders",
+		"/static/js/app.js": "/static/*filepath",
+	} {
+		route, ok := m.match(path)
+		assert.True(t, ok)
+		assert.DeepEqual(t, want, route)
+	}
+	for _, path := range []string{"/users", "/users//orders", "/users/123/items"} {
+		_, ok := m.match(path)
+		assert.False(t, ok)
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/cloudwego/hertz/pkg/common/tracer/stats"
 	"github.com/cloudwego/hertz/pkg/common/tracer/traceinfo"
+	"github.com/cloudwego/hertz/pkg/protocol"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
@@ -91,3 +92,19 @@ func recordErrorSpanWithStack(span trace.Span, err error, stackMessage, stackTra
 		trace.WithAttributes(attributes...),
 	)
 }
+
+// requestBodySize returns the size of the request body, -1 when the size of a body stream is unknown
+func requestBodySize(req *protocol.Request) int {
+	if req.IsBodyStream() {
+		return req.Header.ContentLength()
+	}
+	return len(req.BodyBytes())
+}
+
+// responseBodySize returns the size of the response body, -1 when the size of a body stream is unknown
+func responseBodySize(resp *protocol.Response) int {
+	if resp.IsBodyStream() {
+		return resp.Header.ContentLength()
+	}
+	return len(resp.BodyBytes())
+}
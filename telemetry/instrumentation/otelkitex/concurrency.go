@@ -0,0 +1,103 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"github.com/cloudwego/kitex/pkg/limiter"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/stats"
+)
+
+var _ limiter.LimitReporter = (*KitexTracer)(nil)
+
+type inFlightContextKeyType struct{}
+
+var inFlightContextKey inFlightContextKeyType
+
+// inFlight holds the labels of a server call counted as in flight, the method of the call
+// is only known once the request is decoded so it is counted by the server middleware
+type inFlight struct {
+	labels []label.CwLabel
+}
+
+func withInFlight(ctx context.Context) context.Context {
+	return context.WithValue(ctx, inFlightContextKey, &inFlight{})
+}
+
+func inFlightFromContext(ctx context.Context) *inFlight {
+	if f, ok := ctx.Value(inFlightContextKey).(*inFlight); ok {
+		return f
+	}
+	return nil
+}
+
+func concurrencyLabels(ri rpcinfo.RPCInfo) []label.CwLabel {
+	return []label.CwLabel{
+		{Key: semantic.LabelRPCCalleeKey, Value: defaultValIfEmpty(ri.To().ServiceName(), semantic.UnknownLabelValue)},
+		{Key: semantic.LabelRPCMethodKey, Value: defaultValIfEmpty(rpcMethodName(ri), semantic.UnknownLabelValue)},
+	}
+}
+
+// startInFlight counts the server call as in flight until the server tracer finishes
+func startInFlight(ctx context.Context, cfg *Config) {
+	f := inFlightFromContext(ctx)
+	ri := rpcinfo.GetRPCInfo(ctx)
+	if f == nil || f.labels != nil || ri == nil || cfg.measure == nil || cfg.shouldIgnoreMetrics(ri) {
+		return
+	}
+	f.labels = concurrencyLabels(ri)
+	cfg.measure.Add(ctx, semantic.RPCInFlight, 1, f.labels...)
+}
+
+// finishInFlight stops counting the server call as in flight
+func (s *KitexTracer) finishInFlight(ctx context.Context) {
+	if f := inFlightFromContext(ctx); f != nil && f.labels != nil {
+		s.measure.Add(ctx, semantic.RPCInFlight, -1, f.labels...)
+		f.labels = nil
+	}
+}
+
+// recordQueueWait records the duration from the decoding of the request to the start of the handler,
+// the time spent in the server middlewares and waiting for a worker
+func (s *KitexTracer) recordQueueWait(ctx context.Context, ri rpcinfo.RPCInfo) {
+	readFinish, handleStart := ri.Stats().GetEvent(stats.ReadFinish), ri.Stats().GetEvent(stats.ServerHandleStart)
+	if readFinish == nil || handleStart == nil {
+		return
+	}
+	s.measure.Record(ctx, semantic.RPCQueueWait,
+		float64(handleStart.Time().Sub(readFinish.Time()))/float64(time.Millisecond), concurrencyLabels(ri)...)
+}
+
+// ConnOverloadReport counts the connections rejected by the server limiter, see server.WithLimitReporter
+func (s *KitexTracer) ConnOverloadReport() {
+	s.reportLimitRejection(semantic.LimitConnection)
+}
+
+// QPSOverloadReport counts the requests rejected by the server limiter, see server.WithLimitReporter
+func (s *KitexTracer) QPSOverloadReport() {
+	s.reportLimitRejection(semantic.LimitQPS)
+}
+
+func (s *KitexTracer) reportLimitRejection(limit string) {
+	if !s.cfg.enableConcurrencyMetrics || s.measure == nil {
+		return
+	}
+	s.measure.Inc(context.Background(), semantic.RPCLimitRejection, label.CwLabel{Key: semantic.LabelKeyLimit, Value: limit})
+}
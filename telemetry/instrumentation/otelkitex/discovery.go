@@ -0,0 +1,160 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"github.com/cloudwego/kitex/pkg/discovery"
+	"github.com/cloudwego/kitex/pkg/loadbalance"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	_ discovery.Resolver       = (*resolver)(nil)
+	_ loadbalance.Loadbalancer = (*loadbalancer)(nil)
+	_ loadbalance.Rebalancer   = (*rebalancer)(nil)
+	_ loadbalance.Picker       = (*picker)(nil)
+)
+
+// resolveEventName is the name of the span event of the service discovery lookups
+const resolveEventName = "resolve"
+
+type resolver struct {
+	discovery.Resolver
+	cfg *Config
+}
+
+// NewResolver wraps the resolver to record the latency of the lookups and the number of the
+// resolved instances, use it with client.WithResolver. The lookups made while a call is
+// started are added as span events to the span of the call.
+func NewResolver(r discovery.Resolver, opts ...Option) discovery.Resolver {
+	return &resolver{
+		Resolver: r,
+		cfg:      NewConfig(opts),
+	}
+}
+
+// Resolve records the lookup of desc
+func (r *resolver) Resolve(ctx context.Context, desc string) (discovery.Result, error) {
+	start := time.Now()
+	res, err := r.Resolver.Resolve(ctx, desc)
+	elapsed := time.Since(start)
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		attrs := []attribute.KeyValue{
+			semantic.RPCResolverKey.String(r.Resolver.Name()),
+			semantic.RPCResolveTargetKey.String(desc),
+		}
+		if err != nil {
+			attrs = append(attrs, semconv.ExceptionMessageKey.String(err.Error()))
+		} else {
+			attrs = append(attrs, semantic.RPCResolveInstancesKey.Int(len(res.Instances)))
+		}
+		span.AddEvent(resolveEventName, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	}
+
+	if r.cfg.measure == nil {
+		return res, err
+	}
+	labels := []label.CwLabel{
+		{Key: semantic.LabelKeyResolver, Value: defaultValIfEmpty(r.Resolver.Name(), semantic.UnknownLabelValue)},
+		{Key: semantic.LabelKeyTarget, Value: defaultValIfEmpty(desc, semantic.UnknownLabelValue)},
+	}
+	status := semantic.StatusSucceed
+	if err != nil {
+		status = semantic.StatusError
+	}
+	r.cfg.measure.Record(ctx, semantic.RPCResolveLatency, float64(elapsed)/float64(time.Millisecond),
+		append(labels, label.CwLabel{Key: semantic.LabelKeyStatus, Value: status})...)
+	if err == nil {
+		// the resolvers of the clients sharing a target record the same count
+		r.cfg.measure.Record(ctx, semantic.RPCInstanceCount, float64(len(res.Instances)), labels...)
+	}
+	return res, err
+}
+
+type loadbalancer struct {
+	loadbalance.Loadbalancer
+	cfg *Config
+}
+
+// rebalancer keeps the rebalancing of the wrapped load balancer when the instances change
+type rebalancer struct {
+	*loadbalancer
+	loadbalance.Rebalancer
+}
+
+// NewLoadbalancer wraps the load balancer to count the picked instances by address and to set
+// the address on the span of the call, use it with client.WithLoadBalancer.
+func NewLoadbalancer(lb loadbalance.Loadbalancer, opts ...Option) loadbalance.Loadbalancer {
+	l := &loadbalancer{
+		Loadbalancer: lb,
+		cfg:          NewConfig(opts),
+	}
+	if rb, ok := lb.(loadbalance.Rebalancer); ok {
+		return &rebalancer{loadbalancer: l, Rebalancer: rb}
+	}
+	return l
+}
+
+// GetPicker wraps the picker of the result
+func (l *loadbalancer) GetPicker(res discovery.Result) loadbalance.Picker {
+	return &picker{Picker: l.Loadbalancer.GetPicker(res), lb: l}
+}
+
+type picker struct {
+	loadbalance.Picker
+	lb *loadbalancer
+}
+
+// Next records the picked instance
+func (p *picker) Next(ctx context.Context, request interface{}) discovery.Instance {
+	ins := p.Picker.Next(ctx, request)
+	if ins == nil || ins.Address() == nil {
+		return ins
+	}
+	addr := ins.Address().String()
+	name := p.lb.Loadbalancer.Name()
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(
+			semantic.RPCLoadbalancerKey.String(name),
+			semantic.RPCInstanceAddressKey.String(addr),
+		)
+	}
+
+	cfg := p.lb.cfg
+	ri := rpcinfo.GetRPCInfo(ctx)
+	if cfg.measure == nil || (ri != nil && cfg.shouldIgnoreMetrics(ri)) {
+		return ins
+	}
+	callee := semantic.UnknownLabelValue
+	if ri != nil && ri.To() != nil {
+		callee = defaultValIfEmpty(ri.To().ServiceName(), semantic.UnknownLabelValue)
+	}
+	cfg.measure.Inc(ctx, semantic.RPCInstancePick,
+		label.CwLabel{Key: semantic.LabelRPCCalleeKey, Value: callee},
+		label.CwLabel{Key: semantic.LabelKeyBalancer, Value: defaultValIfEmpty(name, semantic.UnknownLabelValue)},
+		label.CwLabel{Key: semantic.LabelKeyInstance, Value: addr},
+	)
+	return ins
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/discovery"
+	"github.com/cloudwego/kitex/pkg/loadbalance"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/stats"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/internal/testutil"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+)
+
+type testResolver struct {
+	discovery.Resolver
+	instances []discovery.Instance
+}
+
+func (r *testResolver) Resolve(ctx context.Context, desc string) (discovery.Result, error) {
+	return discovery.Result{CacheKey: desc, Instances: r.instances}, nil
+}
+
+func (r *testResolver) Name() string {
+	return "test"
+}
+
+type testLoadbalancer struct {
+	rebalanced int
+}
+
+func (l *testLoadbalancer) GetPicker(res discovery.Result) loadbalance.Picker {
+	return testPicker{res.Instances[0]}
+}
+
+func (l *testLoadbalancer) Name() string {
+	return "first"
+}
+
+func (l *testLoadbalancer) Rebalance(discovery.Change) {
+	l.rebalanced++
+}
+
+func (l *testLoadbalancer) Delete(discovery.Change) {}
+
+type testPicker struct {
+	ins discovery.Instance
+}
+
+func (p testPicker) Next(ctx context.Context, request interface{}) discovery.Instance {
+	return p.ins
+}
+
+func TestResolverAndLoadbalancer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	measure := &testutil.RecordingMeasure{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := tp.Tracer(instrumentationName).Start(context.Background(), "call")
+	ctx = rpcinfo.NewCtxWithRPCInfo(ctx, newTestRPCInfo(stats.LevelDetailed))
+
+	ins := discovery.NewInstance("tcp", "10.0.0.1:8888", 10, nil)
+	r := &testResolver{instances: []discovery.Instance{ins, discovery.NewInstance("tcp", "10.0.0.2:8888", 10, nil)}}
+	resolver := NewResolver(r, WithMeasure(measure))
+	res, err := resolver.Resolve(ctx, "callee")
+	assert.Nil(t, err)
+	// the refreshes of the cache are not made by the calls
+	r.instances = r.instances[:1]
+	_, err = resolver.Resolve(context.Background(), "callee")
+	assert.Nil(t, err)
+
+	inner := &testLoadbalancer{}
+	lb := NewLoadbalancer(inner, WithMeasure(measure))
+	assert.Equal(t, "first", lb.Name())
+	lb.(loadbalance.Rebalancer).Rebalance(discovery.Change{})
+	assert.Equal(t, 1, inner.rebalanced)
+	assert.Equal(t, ins, lb.GetPicker(res).Next(ctx, nil))
+	span.End()
+
+	targetLabels := []label.CwLabel{{Key: semantic.LabelKeyResolver, Value: "test"}, {Key: semantic.LabelKeyTarget, Value: "callee"}}
+	assert.Len(t, measure.Records[semantic.RPCResolveLatency], 2)
+	assert.Equal(t, []float64{2, 1}, measure.Records[semantic.RPCInstanceCount])
+	assert.Equal(t, targetLabels, measure.Labels[semantic.RPCInstanceCount][0])
+	assert.Equal(t, [][]label.CwLabel{{
+		{Key: semantic.LabelRPCCalleeKey, Value: "callee"},
+		{Key: semantic.LabelKeyBalancer, Value: "first"},
+		{Key: semantic.LabelKeyInstance, Value: "10.0.0.1:8888"},
+	}}, measure.Labels[semantic.RPCInstancePick])
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		if assert.Len(t, spans[0].Events(), 1) {
+			assert.Equal(t, resolveEventName, spans[0].Events()[0].Name)
+			assert.Contains(t, spans[0].Events()[0].Attributes, semantic.RPCResolveInstancesKey.Int(2))
+		}
+		assert.Contains(t, spans[0].Attributes(), semantic.RPCInstanceAddressKey.String("10.0.0.1:8888"))
+	}
+}
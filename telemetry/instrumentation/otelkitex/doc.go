@@ -13,4 +13,13 @@
 // limitations under the License.
 
 // Package otelkitex provides the otel otelkitex & meter implement of tracer
+//
+// The trace context is propagated in the headers of the transport:
+//   - TTHeader and TTHeaderFramed (NewClientSuite) propagate it in the TTHeader meta info
+//   - gRPC and HTTP2 (NewGRPCClientSuite) propagate it in the HTTP2 headers
+//   - Framed (NewFramedClientSuite) and Buffered (NewBufferedClientSuite) Thrift have no headers,
+//     the trace context is lost unless WithRequestCarrier propagates it inside the requests
+//
+// The servers use NewServerSuite for every transport, with the same WithRequestCarrier option
+// as the clients when the trace context is carried by the requests.
 package otelkitex
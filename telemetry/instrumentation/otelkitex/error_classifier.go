@@ -0,0 +1,89 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"errors"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"github.com/cloudwego/kitex/pkg/kerrors"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrorClassifier returns the error type of a finished call, one of the semantic.ErrorType values
+// or any other stable value of low cardinality
+type ErrorClassifier func(ri rpcinfo.RPCInfo) string
+
+// DefaultErrorClassifier classifies the kitex errors of a call
+func DefaultErrorClassifier(ri rpcinfo.RPCInfo) string {
+	if panicked, _ := ri.Stats().Panicked(); panicked {
+		return semantic.ErrorTypePanic
+	}
+
+	err := ri.Stats().Error()
+	if err == nil {
+		if ri.Invocation() != nil && ri.Invocation().BizStatusErr() != nil {
+			return semantic.ErrorTypeBiz
+		}
+		return semantic.ErrorTypeNone
+	}
+
+	if _, ok := kerrors.FromBizStatusError(err); ok || errors.Is(err, kerrors.ErrBiz) {
+		return semantic.ErrorTypeBiz
+	}
+
+	switch {
+	case errors.Is(err, kerrors.ErrPanic):
+		return semantic.ErrorTypePanic
+	case kerrors.IsTimeoutError(err), errors.Is(err, kerrors.ErrTimeoutByBusiness):
+		return semantic.ErrorTypeTimeout
+	case errors.Is(err, kerrors.ErrCircuitBreak):
+		return semantic.ErrorTypeCircuitBreak
+	case errors.Is(err, kerrors.ErrACL):
+		return semantic.ErrorTypeACL
+	case errors.Is(err, kerrors.ErrGetConnection), errors.Is(err, kerrors.ErrRemoteOrNetwork):
+		return semantic.ErrorTypeConnection
+	default:
+		return semantic.ErrorTypeOther
+	}
+}
+
+// errorAttributes returns the error type and biz status span attributes of a call
+func errorAttributes(ri rpcinfo.RPCInfo, errorType string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if errorType != semantic.ErrorTypeNone {
+		attrs = append(attrs, semantic.ErrorTypeKey.String(errorType))
+	}
+
+	bizErr := bizStatusError(ri)
+	if bizErr != nil {
+		attrs = append(attrs,
+			semantic.RPCBizStatusCodeKey.Int64(int64(bizErr.BizStatusCode())),
+			semantic.RPCBizStatusMessageKey.String(bizErr.BizMessage()),
+		)
+	}
+	return attrs
+}
+
+func bizStatusError(ri rpcinfo.RPCInfo) kerrors.BizStatusErrorIface {
+	if ri.Invocation() != nil && ri.Invocation().BizStatusErr() != nil {
+		return ri.Invocation().BizStatusErr()
+	}
+	if bizErr, ok := kerrors.FromBizStatusError(ri.Stats().Error()); ok {
+		return bizErr
+	}
+	return nil
+}
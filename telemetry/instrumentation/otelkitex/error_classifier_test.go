@@ -0,0 +1,106 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"github.com/cloudwego/kitex/pkg/kerrors"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/stats"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestDefaultErrorClassifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		panicked  bool
+		bizErr    kerrors.BizStatusErrorIface
+		want      string
+		wantAttrs []attribute.KeyValue
+	}{
+		{
+			name: "succeed",
+			want: semantic.ErrorTypeNone,
+		},
+		{
+			name:   "biz status error",
+			bizErr: kerrors.NewBizStatusError(1001, "not found"),
+			want:   semantic.ErrorTypeBiz,
+			wantAttrs: []attribute.KeyValue{
+				semantic.ErrorTypeKey.String(semantic.ErrorTypeBiz),
+				semantic.RPCBizStatusCodeKey.Int64(1001),
+				semantic.RPCBizStatusMessageKey.String("not found"),
+			},
+		},
+		{
+			name: "timeout",
+			err:  kerrors.ErrRPCTimeout.WithCause(errors.New("1s")),
+			want: semantic.ErrorTypeTimeout,
+		},
+		{
+			name: "circuit break",
+			err:  kerrors.ErrInstanceCircuitBreak,
+			want: semantic.ErrorTypeCircuitBreak,
+		},
+		{
+			name: "acl",
+			err:  kerrors.ErrACL.WithCause(errors.New("denied")),
+			want: semantic.ErrorTypeACL,
+		},
+		{
+			name: "connection",
+			err:  kerrors.ErrGetConnection.WithCause(errors.New("dial tcp: refused")),
+			want: semantic.ErrorTypeConnection,
+		},
+		{
+			name:     "panic",
+			err:      errors.New("boom"),
+			panicked: true,
+			want:     semantic.ErrorTypePanic,
+		},
+		{
+			name:      "other",
+			err:       errors.New("boom"),
+			want:      semantic.ErrorTypeOther,
+			wantAttrs: []attribute.KeyValue{semantic.ErrorTypeKey.String(semantic.ErrorTypeOther)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ri := newTestRPCInfo(stats.LevelDetailed)
+			st := rpcinfo.AsMutableRPCStats(ri.Stats())
+			if tt.err != nil {
+				st.SetError(tt.err)
+			}
+			if tt.panicked {
+				st.SetPanicked(tt.err)
+			}
+			if tt.bizErr != nil {
+				ri.Invocation().(rpcinfo.InvocationSetter).SetBizStatusErr(tt.bizErr)
+			}
+
+			got := DefaultErrorClassifier(ri)
+			assert.Equal(t, tt.want, got)
+			if tt.wantAttrs != nil {
+				assert.Equal(t, tt.wantAttrs, errorAttributes(ri, got))
+			}
+		})
+	}
+}
@@ -15,6 +15,11 @@
 package otelkitex
 
 import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
 	"github.com/cloudwego/kitex/pkg/rpcinfo"
 	"github.com/cloudwego/kitex/pkg/stats"
@@ -35,17 +40,79 @@ var commonEvents = map[string]stats.Event{
 	"write_finish":         stats.WriteFinish,
 }
 
-func injectStatsEventsToSpan(span trace.Span, st rpcinfo.RPCStats) {
+// StatsEventFilter reports whether a stats event is added as an event of the span, name is the name of the span event
+type StatsEventFilter func(name string, event rpcinfo.Event) bool
+
+type namedEvent struct {
+	name  string
+	event rpcinfo.Event
+}
+
+// injectStatsEventsToSpan adds the recorded stats events to the span in the order they happened
+func injectStatsEventsToSpan(span trace.Span, st rpcinfo.RPCStats, cfg *Config) {
+	events := make([]namedEvent, 0, len(commonEvents)+len(cfg.statsEvents))
+	collect := func(name string, event stats.Event) {
+		gotEvent := st.GetEvent(event)
+		if gotEvent == nil || (cfg.statsEventFilter != nil && !cfg.statsEventFilter(name, gotEvent)) {
+			return
+		}
+		events = append(events, namedEvent{name: name, event: gotEvent})
+	}
 	for name, event := range commonEvents {
-		if gotEvent := st.GetEvent(event); gotEvent != nil {
-			attrs := []attribute.KeyValue{attribute.Int(semantic.LabelKeyStatus, int(gotEvent.Status()))}
-			if gotEvent.Info() != "" {
-				attrs = append(attrs, attribute.String("event.info", gotEvent.Info()))
+		if _, ok := cfg.statsEvents[name]; !ok {
+			collect(name, event)
+		}
+	}
+	for name, event := range cfg.statsEvents {
+		collect(name, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		ti, tj := events[i].event.Time(), events[j].event.Time()
+		if ti.Equal(tj) {
+			return events[i].name < events[j].name
+		}
+		return ti.Before(tj)
+	})
+
+	for _, e := range events {
+		attrs := []attribute.KeyValue{attribute.Int(semantic.LabelKeyStatus, int(e.event.Status()))}
+		if e.event.Info() != "" {
+			attrs = append(attrs, attribute.String("event.info", e.event.Info()))
+		}
+		span.AddEvent(e.name,
+			trace.WithTimestamp(e.event.Time()),
+			trace.WithAttributes(attrs...),
+		)
+	}
+}
+
+// transportPhases are the phases of a call measured from its stats events, the first recorded
+// start event is used so that the read phase includes the wait for the response on the client
+var transportPhases = []struct {
+	metric string
+	starts []stats.Event
+	finish stats.Event
+}{
+	{semantic.RPCConnectDuration, []stats.Event{stats.ClientConnStart}, stats.ClientConnFinish},
+	{semantic.RPCWriteDuration, []stats.Event{stats.WriteStart}, stats.WriteFinish},
+	{semantic.RPCReadDuration, []stats.Event{stats.WaitReadStart, stats.ReadStart}, stats.ReadFinish},
+	{semantic.RPCHandleDuration, []stats.Event{stats.ServerHandleStart}, stats.ServerHandleFinish},
+}
+
+// recordTransportMetrics records the duration of the phases of the call, the events are only
+// recorded with the detailed stats level
+func (s *KitexTracer) recordTransportMetrics(ctx context.Context, st rpcinfo.RPCStats, labels []label.CwLabel) {
+	for _, phase := range transportPhases {
+		finish := st.GetEvent(phase.finish)
+		if finish == nil {
+			continue
+		}
+		for _, event := range phase.starts {
+			if start := st.GetEvent(event); start != nil {
+				s.measure.Record(ctx, phase.metric, float64(finish.Time().Sub(start.Time()))/float64(time.Millisecond), labels...)
+				break
 			}
-			span.AddEvent(name,
-				trace.WithTimestamp(gotEvent.Time()),
-				trace.WithAttributes(attrs...),
-			)
 		}
 	}
 }
@@ -0,0 +1,97 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/internal"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+// httpGenericRequest is implemented by the requests of the HTTP generic calls
+type httpGenericRequest interface {
+	GetMethod() string
+	GetPath() string
+}
+
+// isGenericCall reports whether the call is made through a kitex generic client or server
+func isGenericCall(ri rpcinfo.RPCInfo) bool {
+	inv := ri.Invocation()
+	return inv != nil && (inv.ServiceName() == serviceinfo.GenericService || inv.MethodName() == serviceinfo.GenericMethod)
+}
+
+// rpcServiceName returns the service of the call, the generic calls use the name of the kitex service
+// instead of the placeholder of the generic service info
+func rpcServiceName(ri rpcinfo.RPCInfo) string {
+	if isGenericCall(ri) && ri.To() != nil && ri.To().ServiceName() != "" {
+		return ri.To().ServiceName()
+	}
+	return ri.Invocation().ServiceName()
+}
+
+// rpcMethodName returns the method of the call, skipping the placeholder method of the generic service info
+func rpcMethodName(ri rpcinfo.RPCInfo) string {
+	method := ri.Invocation().MethodName()
+	if (method == "" || method == serviceinfo.GenericMethod) && ri.To() != nil && ri.To().Method() != "" {
+		return ri.To().Method()
+	}
+	return method
+}
+
+// genericType returns the representation of the request of a generic call
+func genericType(req interface{}) string {
+	switch req.(type) {
+	case string:
+		return "json"
+	case []byte:
+		return "binary"
+	case map[string]interface{}:
+		return "map"
+	case httpGenericRequest:
+		return "http"
+	default:
+		return "other"
+	}
+}
+
+// setGenericSpanAttributes adds the generic type and the HTTP route of the generic calls to the span of the call
+func setGenericSpanAttributes(ctx context.Context, req interface{}) {
+	ri := rpcinfo.GetRPCInfo(ctx)
+	if ri == nil || !isGenericCall(ri) {
+		return
+	}
+	tc := internal.TraceCarrierFromContext(ctx)
+	if tc == nil || tc.Span() == nil || !tc.Span().IsRecording() {
+		return
+	}
+
+	req = requestOf(req)
+	attrs := []attribute.KeyValue{
+		semantic.RPCGenericKey.Bool(true),
+		semantic.RPCGenericTypeKey.String(genericType(req)),
+	}
+	if httpReq, ok := req.(httpGenericRequest); ok {
+		attrs = append(attrs,
+			semconv.HTTPMethodKey.String(httpReq.GetMethod()),
+			semconv.HTTPTargetKey.String(httpReq.GetPath()),
+		)
+	}
+	tc.Span().SetAttributes(attrs...)
+}
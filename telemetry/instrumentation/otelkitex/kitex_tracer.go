@@ -14,6 +14,7 @@ package otelkitex
 
 import (
 	"context"
+	"net"
 	"strconv"
 	"time"
 
@@ -21,10 +22,13 @@ import (
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	"go.opentelemetry.io/otel/trace"
 
 	cwmetric "github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/metric"
 	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
 	"github.com/cloudwego/kitex/pkg/stats"
 )
 
@@ -34,6 +38,9 @@ type KitexTracer struct {
 	measure               cwmetric.Measure
 	cfg                   *Config
 	recordSourceOperation bool
+	// spanKind is trace.SpanKindClient for client tracers, which start the span of the call,
+	// server spans are started by ServerMiddleware
+	spanKind trace.SpanKind
 }
 
 // Start record the beginning of an RPC invocation.
@@ -43,11 +50,106 @@ func (s *KitexTracer) Start(ctx context.Context) context.Context {
 		tc.SetTracer(s.cfg.tracer)
 	}
 
-	return internal.WithTraceCarrier(ctx, tc)
+	if s.spanKind == trace.SpanKindClient {
+		if s.cfg.tracer != nil && !s.cfg.shouldIgnoreTracing(rpcinfo.GetRPCInfo(ctx)) {
+			ctx = startClientSpan(ctx, s.cfg, tc)
+		}
+		if s.cfg.enableRetryAttempts {
+			ctx = withRetryStats(ctx)
+		}
+	} else {
+		ctx = withInFlight(ctx)
+	}
+
+	return withStreamStats(internal.WithTraceCarrier(ctx, tc))
+}
+
+// startClientSpan starts the span of an outgoing call, ClientMiddleware injects it into the request meta
+func startClientSpan(ctx context.Context, cfg *Config, tc *internal.TraceCarrier) context.Context {
+	ri := rpcinfo.GetRPCInfo(ctx)
+	if ri == nil || ri.Stats() == nil || ri.Stats().Level() == stats.LevelDisabled {
+		return ctx
+	}
+
+	opts := []trace.SpanStartOption{
+		trace.WithTimestamp(getStartTimeOrNow(ri)),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(rpcSpanAttributes(ri, trace.SpanKindClient)...),
+		trace.WithLinks(linksFromContext(ctx)...),
+	}
+	opts = append(opts, oneWayRootSpanOptions(cfg, ri, trace.SpanContextFromContext(ctx))...)
+
+	ctx, span := cfg.tracer.Start(ctx, cfg.spanNameFormatter(ri), opts...)
+	tc.SetSpan(span)
+
+	return ctx
+}
+
+// rpcSpanAttributes returns the rpc semantic convention attributes of the call known from the rpc info,
+// the peer address of the client calls is only known once the instance is resolved
+func rpcSpanAttributes(ri rpcinfo.RPCInfo, kind trace.SpanKind) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semantic.RPCSystemKitex,
+		semconv.RPCServiceKey.String(rpcServiceName(ri)),
+		semconv.RPCMethodKey.String(rpcMethodName(ri)),
+	}
+	if ri.Config() != nil {
+		if codec := payloadCodecName(ri.Config().PayloadCodec()); codec != "" {
+			attrs = append(attrs, semantic.RPCPayloadCodecKey.String(codec))
+		}
+	}
+
+	peer, host := ri.To(), ri.From()
+	if kind == trace.SpanKindServer {
+		peer, host = ri.From(), ri.To()
+	} else if peer != nil && peer.ServiceName() != "" {
+		// the peer service of the server spans is propagated by the client
+		attrs = append(attrs, semconv.PeerServiceKey.String(peer.ServiceName()))
+	}
+	if peer != nil {
+		attrs = append(attrs, netAttributes(peer.Address(), semconv.NetPeerIPKey, semconv.NetPeerPortKey)...)
+	}
+	if host != nil {
+		attrs = append(attrs, netAttributes(host.Address(), semconv.NetHostIPKey, semconv.NetHostPortKey)...)
+	}
+	return attrs
+}
+
+// netAttributes returns the transport, ip and port attributes of the address
+func netAttributes(addr net.Addr, ipKey, portKey attribute.Key) []attribute.KeyValue {
+	if addr == nil {
+		return nil
+	}
+	if addr.Network() == "unix" {
+		return []attribute.KeyValue{semconv.NetTransportUnix}
+	}
+	ip, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	attrs := []attribute.KeyValue{semconv.NetTransportTCP, ipKey.String(ip)}
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, portKey.Int(p))
+	}
+	return attrs
+}
+
+func payloadCodecName(codec serviceinfo.PayloadCodec) string {
+	switch codec {
+	case serviceinfo.Thrift:
+		return "thrift"
+	case serviceinfo.Protobuf:
+		return "protobuf"
+	case serviceinfo.Hessian2:
+		return "hessian2"
+	}
+	return ""
 }
 
 // Finish record after receiving the response of server.
 func (s *KitexTracer) Finish(ctx context.Context) {
+	s.finishInFlight(ctx)
+
 	// rpc info
 	ri := rpcinfo.GetRPCInfo(ctx)
 	if ri.Stats().Level() == stats.LevelDisabled {
@@ -60,12 +162,23 @@ func (s *KitexTracer) Finish(ctx context.Context) {
 	duration := rpcFinish.Time().Sub(rpcStart.Time())
 	elapsedTime := float64(duration) / float64(time.Millisecond)
 
-	caller := ri.From()
+	ignoreMetrics := s.cfg.shouldIgnoreMetrics(ri)
+
+	tc := internal.TraceCarrierFromContext(ctx)
+
+	// the caller of the servers without basic info is named by the propagated peer service
+	callerName := ri.From().ServiceName()
+	var peerService []attribute.KeyValue
+	if s.spanKind == trace.SpanKindServer && tc != nil {
+		peerService = tc.PeerService()
+		callerName = defaultValIfEmpty(callerName, peerServiceValue(peerService, semconv.PeerServiceKey))
+	}
+
 	callee := ri.To()
 	labels := []label.CwLabel{
 		{
 			Key:   semantic.LabelRPCCallerKey,
-			Value: defaultValIfEmpty(caller.ServiceName(), semantic.UnknownLabelValue),
+			Value: defaultValIfEmpty(callerName, semantic.UnknownLabelValue),
 		},
 		{
 			Key:   semantic.LabelRPCCalleeKey,
@@ -73,11 +186,11 @@ func (s *KitexTracer) Finish(ctx context.Context) {
 		},
 		{
 			Key:   semantic.LabelRPCMethodKey,
-			Value: defaultValIfEmpty(callee.Method(), semantic.UnknownLabelValue),
+			Value: defaultValIfEmpty(rpcMethodName(ri), semantic.UnknownLabelValue),
 		},
 	}
 
-	if retriedCnt, ok := callee.Tag(rpcinfo.RetryTag); ok {
+	if retriedCnt, ok := callee.Tag(rpcinfo.RetryTag); ok && !ignoreMetrics {
 		retryAttempts, err := strconv.Atoi(retriedCnt)
 		if err == nil {
 			s.measure.Record(ctx, semantic.RPCRetry, float64(retryAttempts), labels...)
@@ -85,11 +198,25 @@ func (s *KitexTracer) Finish(ctx context.Context) {
 
 	}
 
+	errorType := s.cfg.errorClassifier(ri)
+	labels = append(labels, label.CwLabel{Key: semantic.LabelKeyErrorType, Value: errorType})
+
 	if s.cfg.labelFunc != nil {
 		labels = append(labels, s.cfg.labelFunc(ri)...)
 	}
 
-	tc := internal.TraceCarrierFromContext(ctx)
+	if s.spanKind == trace.SpanKindServer && s.cfg.callerPeerLabels {
+		labels = append(labels, callerPeerLabels(peerService)...)
+	}
+
+	if len(s.cfg.baggageLabelKeys) > 0 {
+		// the server baggage is extracted by the server middleware
+		bags := baggage.FromContext(ctx)
+		if s.spanKind == trace.SpanKindServer && tc != nil {
+			bags = tc.Baggage()
+		}
+		labels = append(labels, internal.BaggageLabels(bags, s.cfg.baggageLabelKeys)...)
+	}
 
 	// span
 	var span trace.Span
@@ -97,20 +224,27 @@ func (s *KitexTracer) Finish(ctx context.Context) {
 		span = tc.Span()
 		// span attributes
 		attrs := []attribute.KeyValue{
-			semantic.RPCSystemKitex,
 			semantic.RPCSystemKitexRecvSize.Int64(int64(st.RecvSize())),
 			semantic.RPCSystemKitexSendSize.Int64(int64(st.SendSize())),
 			semantic.RequestProtocolKey.String(ri.Config().TransportProtocol().String()),
 		}
 
+		// the rpc attributes are set when the span starts,
+		// except the address of the client instance which is only resolved once the call is sent
+		if s.spanKind == trace.SpanKindClient && callee.Address() != nil {
+			attrs = append(attrs, netAttributes(callee.Address(), semconv.NetPeerIPKey, semconv.NetPeerPortKey)...)
+		}
+
 		// The source operation dimension maybe cause high cardinality issues
 		if s.recordSourceOperation {
 			attrs = append(attrs, semantic.SourceOperationKey.String(ri.From().Method()))
 		}
 
+		attrs = append(attrs, errorAttributes(ri, errorType)...)
+
 		span.SetAttributes(attrs...)
 
-		injectStatsEventsToSpan(span, st)
+		injectStatsEventsToSpan(span, st, s.cfg)
 
 		if panicMsg, panicStack, rpcErr := parseRPCError(ri); rpcErr != nil || len(panicMsg) > 0 {
 			recordErrorSpanWithStack(span, rpcErr, panicMsg, panicStack)
@@ -137,9 +271,26 @@ func (s *KitexTracer) Finish(ctx context.Context) {
 		labels = append(labels, stateless)
 	}
 
+	if ignoreMetrics {
+		return
+	}
+
 	// measure
 	s.measure.Inc(ctx, semantic.RPCCounter, labels...)
 	s.measure.Record(ctx, semantic.RPCLatency, elapsedTime, labels...)
+	s.recordStreamMetrics(ctx, ri, elapsedTime, labels)
+	s.recordTransportMetrics(ctx, st, labels)
+	if s.spanKind == trace.SpanKindServer && s.cfg.enableConcurrencyMetrics {
+		s.recordQueueWait(ctx, ri)
+	}
+
+	// the server receives the request and sends the response, the client the other way around
+	requestSize, responseSize := st.RecvSize(), st.SendSize()
+	if s.spanKind == trace.SpanKindClient {
+		requestSize, responseSize = st.SendSize(), st.RecvSize()
+	}
+	s.measure.Record(ctx, semantic.RPCRequestSize, float64(requestSize), labels...)
+	s.measure.Record(ctx, semantic.RPCResponseSize, float64(responseSize), labels...)
 }
 
 func defaultValIfEmpty(val, def string) string {
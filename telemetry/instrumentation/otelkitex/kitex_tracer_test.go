@@ -0,0 +1,805 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/gopkg/cloud/metainfo"
+	"github.com/cloudwego/kitex/client"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/metadata"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/cloudwego/kitex/pkg/stats"
+	"github.com/cloudwego/kitex/pkg/streaming"
+	"github.com/cloudwego/kitex/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/internal"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/internal/testutil"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+)
+
+func newTestRPCInfo(level stats.Level) rpcinfo.RPCInfo {
+	addr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:8888")
+	st := rpcinfo.NewRPCStats()
+	rpcinfo.AsMutableRPCStats(st).SetLevel(level)
+	return rpcinfo.NewRPCInfo(
+		rpcinfo.NewEndpointInfo("caller", "CallerMethod", nil, nil),
+		rpcinfo.NewEndpointInfo("callee", "Echo", addr, nil),
+		rpcinfo.NewInvocation("EchoService", "Echo", "echo"),
+		rpcinfo.NewRPCConfig(),
+		st,
+	)
+}
+
+func TestClientTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	cfg := DefaultConfig()
+	cfg.tracerProvider = tracerProvider
+	cfg.textMapPropagator = propagation.TraceContext{}
+	cfg.tracer = tracerProvider.Tracer(instrumentationName)
+	measure := &testutil.RecordingMeasure{}
+	tracer := &KitexTracer{measure: measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	rpcinfo.AsMutableRPCStats(ri.Stats()).SetSendSize(128)
+	rpcinfo.AsMutableRPCStats(ri.Stats()).SetRecvSize(512)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+
+	ctx = tracer.Start(ctx)
+	span := trace.SpanFromContext(ctx)
+	assert.True(t, span.IsRecording())
+
+	// the client span is propagated to the callee
+	var injected map[string]string
+	err := ClientMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		injected = metainfo.GetAllValues(ctx)
+		return nil
+	})(ctx, nil, nil)
+	assert.Nil(t, err)
+	_, spanCtx := Extract(context.Background(), cfg, injected)
+	assert.Equal(t, span.SpanContext().SpanID(), spanCtx.SpanID())
+
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "echo.EchoService/Echo", spans[0].Name())
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+		assert.Subset(t, spans[0].Attributes(), []interface{}{
+			semantic.RPCSystemKitex,
+			semconv.RPCServiceKey.String("EchoService"),
+			semconv.RPCMethodKey.String("Echo"),
+			semconv.PeerServiceKey.String("callee"),
+			semconv.NetPeerIPKey.String("127.0.0.1"),
+			semconv.NetPeerPortKey.Int(8888),
+		})
+	}
+	// the address of the instance is not a metric label
+	for _, l := range measure.Labels[semantic.RPCCounter][0] {
+		assert.NotEqual(t, "net_peer_name", l.Key)
+	}
+	// the client sends the request
+	assert.Equal(t, []float64{128}, measure.Records[semantic.RPCRequestSize])
+	assert.Equal(t, []float64{512}, measure.Records[semantic.RPCResponseSize])
+	assert.Empty(t, measure.Records[semantic.RPCStreamDuration])
+}
+
+func TestClientTracerStatsDisabled(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg := DefaultConfig()
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), newTestRPCInfo(stats.LevelDisabled))
+	ctx = tracer.Start(ctx)
+	tracer.Finish(ctx)
+
+	assert.False(t, trace.SpanFromContext(ctx).IsRecording())
+	assert.Empty(t, recorder.Started())
+}
+
+func TestClientMiddlewareNonSDKSpan(t *testing.T) {
+	// spans of other tracer implementations do not expose their resource
+	span := recordingSpan{Span: noop.Span{}}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+
+	called := false
+	err := ClientMiddleware(DefaultConfig())(func(ctx context.Context, req, resp interface{}) error {
+		called = true
+		return nil
+	})(ctx, nil, nil)
+	assert.Nil(t, err)
+	assert.True(t, called)
+}
+
+type recordingSpan struct {
+	noop.Span
+}
+
+func (recordingSpan) IsRecording() bool {
+	return true
+}
+
+type sizedMessage struct{}
+
+func (sizedMessage) Size() int {
+	return 42
+}
+
+type testStream struct {
+	streaming.Stream
+	ctx context.Context
+}
+
+func (s testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestClientTracerStreaming(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	measure := &testutil.RecordingMeasure{}
+	cfg := DefaultConfig()
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	rpcinfo.AsMutableRPCConfig(ri.Config()).SetInteractionMode(rpcinfo.Streaming)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+
+	send := StreamSendMiddleware(func(stream streaming.Stream, message interface{}) error {
+		tracer.ReportStreamEvent(stream.Context(), ri, rpcinfo.NewEvent(stats.StreamSend, stats.StatusInfo, ""))
+		return nil
+	})
+	stream := testStream{ctx: ctx}
+	assert.Nil(t, send(stream, sizedMessage{}))
+	assert.Nil(t, send(stream, struct{}{}))
+	tracer.ReportStreamEvent(ctx, ri, rpcinfo.NewEvent(stats.StreamRecv, stats.StatusError, "EOF"))
+
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		var messages [][]attribute.KeyValue
+		for _, event := range spans[0].Events() {
+			if event.Name == messageEventName {
+				messages = append(messages, event.Attributes)
+			}
+		}
+		assert.Equal(t, [][]attribute.KeyValue{
+			{semconv.MessageTypeSent, semconv.MessageIDKey.Int64(1), semconv.MessageUncompressedSizeKey.Int64(42)},
+			{semconv.MessageTypeSent, semconv.MessageIDKey.Int64(2)},
+			{semconv.MessageTypeReceived, semconv.MessageIDKey.Int64(1), attribute.String("event.info", "EOF")},
+		}, messages)
+	}
+
+	assert.Equal(t, []float64{2}, measure.Records[semantic.RPCRequestsPerRPC])
+	assert.Equal(t, []float64{1}, measure.Records[semantic.RPCResponsesPerRPC])
+	assert.Len(t, measure.Records[semantic.RPCStreamDuration], 1)
+}
+
+func TestClientSuiteStreamMiddlewares(t *testing.T) {
+	for _, suite := range []*clientSuite{NewClientSuite(), NewGRPCClientSuite(), NewFramedClientSuite(), NewBufferedClientSuite()} {
+		o := &client.Options{Configs: rpcinfo.NewRPCConfig()}
+		for _, opt := range suite.Options() {
+			opt.F(o, &utils.Slice{})
+		}
+		if !assert.Len(t, o.Streaming.RecvMiddlewareBuilders, 1) || !assert.Len(t, o.Streaming.SendMiddlewareBuilders, 1) {
+			continue
+		}
+
+		ss := newStreamStats()
+		stream := testStream{ctx: context.WithValue(context.Background(), streamStatsContextKey, ss)}
+		recv := o.Streaming.RecvMiddlewareBuilders[0](context.Background())(func(streaming.Stream, interface{}) error { return nil })
+		send := o.Streaming.SendMiddlewareBuilders[0](context.Background())(func(streaming.Stream, interface{}) error { return nil })
+		assert.Nil(t, recv(stream, sizedMessage{}))
+		assert.Nil(t, send(stream, sizedMessage{}))
+		assert.Equal(t, int64(42), ss.lastReceivedSize.Load())
+		assert.Equal(t, int64(42), ss.lastSentSize.Load())
+	}
+}
+
+func TestClientMiddlewareRetryAttempts(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	measure := &testutil.RecordingMeasure{}
+	cfg := NewConfig([]Option{WithEnableRetryAttempts(), WithMeasure(measure)})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := tracer.Start(rpcinfo.NewCtxWithRPCInfo(context.Background(), ri))
+
+	// the backup request is sent while the first attempt is still waiting for its response
+	backupRI := newTestRPCInfo(stats.LevelDetailed)
+	rpcinfo.AsMutableEndpointInfo(backupRI.To()).SetTag(rpcinfo.RetryTag, "1")
+	mw := ClientMiddleware(cfg)
+	err := mw(func(attemptCtx context.Context, req, resp interface{}) error {
+		return mw(func(context.Context, interface{}, interface{}) error {
+			return nil
+		})(rpcinfo.NewCtxWithRPCInfo(ctx, backupRI), nil, nil)
+	})(ctx, nil, nil)
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "echo.EchoService/Echo attempt", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), semantic.RPCRetryAttemptKey.Int(1))
+		assert.Contains(t, spans[0].Attributes(), semantic.RPCBackupRequestKey.Bool(true))
+		assert.Contains(t, spans[1].Attributes(), semantic.RPCRetryAttemptKey.Int(0))
+		assert.Contains(t, spans[1].Attributes(), semantic.RPCBackupRequestKey.Bool(false))
+		assert.Equal(t, trace.SpanFromContext(ctx).SpanContext().SpanID(), spans[1].Parent().SpanID())
+	}
+
+	if assert.Len(t, measure.Labels[semantic.RPCRetryAttempt], 2) {
+		assert.Contains(t, measure.Labels[semantic.RPCRetryAttempt][0], label.CwLabel{Key: semantic.LabelKeyBackup, Value: "true"})
+		assert.Contains(t, measure.Labels[semantic.RPCRetryAttempt][1], label.CwLabel{Key: semantic.LabelKeyAttempt, Value: "0"})
+	}
+}
+
+func TestClientTracerShouldIgnore(t *testing.T) {
+	isEcho := func(ri rpcinfo.RPCInfo) bool {
+		return ri.To().Method() == "Echo"
+	}
+	tests := []struct {
+		name        string
+		opt         Option
+		wantSpans   int
+		wantMetrics bool
+	}{
+		{name: "ignore both", opt: WithShouldIgnore(isEcho), wantSpans: 0, wantMetrics: false},
+		{name: "ignore tracing", opt: WithShouldIgnoreTracing(isEcho), wantSpans: 0, wantMetrics: true},
+		{name: "ignore metrics", opt: WithShouldIgnoreMetrics(isEcho), wantSpans: 1, wantMetrics: false},
+		{name: "nil condition", opt: WithShouldIgnore(nil), wantSpans: 1, wantMetrics: true},
+		{name: "nil tracing condition", opt: WithShouldIgnoreTracing(nil), wantSpans: 1, wantMetrics: true},
+		{name: "nil metrics condition", opt: WithShouldIgnoreMetrics(nil), wantSpans: 1, wantMetrics: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			measure := &testutil.RecordingMeasure{}
+			cfg := NewConfig([]Option{tt.opt, WithMeasure(measure)})
+			cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+			tracer := &KitexTracer{measure: measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+			ri := newTestRPCInfo(stats.LevelDetailed)
+			ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+			ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+			ctx = tracer.Start(ctx)
+			ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+			tracer.Finish(ctx)
+
+			assert.Len(t, recorder.Ended(), tt.wantSpans)
+			assert.Equal(t, tt.wantMetrics, len(measure.Records[semantic.RPCCounter]) == 1)
+		})
+	}
+}
+
+type echoRequest struct {
+	Tenant string
+}
+
+type echoArgs struct {
+	Req *echoRequest
+}
+
+func (a *echoArgs) GetFirstArgument() interface{} {
+	return a.Req
+}
+
+func TestClientSpanNameAndAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg := NewConfig([]Option{
+		WithMeasure(&testutil.RecordingMeasure{}),
+		WithSpanNameFormatter(func(ri rpcinfo.RPCInfo) string {
+			return "call " + ri.To().Method()
+		}),
+		WithSpanAttributesFunc(func(ri rpcinfo.RPCInfo, req, resp interface{}) []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("tenant", req.(*echoRequest).Tenant)}
+		}),
+	})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	err := ClientMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		return nil
+	})(ctx, &echoArgs{Req: &echoRequest{Tenant: "t1"}}, nil)
+	assert.Nil(t, err)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "call Echo", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("tenant", "t1"))
+	}
+}
+
+func TestNilSpanNameFormatter(t *testing.T) {
+	cfg := NewConfig([]Option{WithSpanNameFormatter(nil)})
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	assert.Equal(t, spanNaming(ri), cfg.spanNameFormatter(ri))
+}
+
+type echoUser struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func TestClientPayloadCapture(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg := NewConfig([]Option{
+		WithMeasure(&testutil.RecordingMeasure{}),
+		WithPayloadCapture(32),
+		WithPayloadRedactor(func(ri rpcinfo.RPCInfo, field string, value interface{}) interface{} {
+			if field == "user.password" {
+				return "***"
+			}
+			return value
+		}),
+	})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	req := map[string]interface{}{"user": &echoUser{Name: "n", Password: "secret"}}
+	resp := strings.Repeat("a", 64)
+	err := ClientMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		return nil
+	})(ctx, req, resp)
+	assert.Nil(t, err)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	payloads := map[string][]attribute.KeyValue{}
+	for _, event := range spans[0].Events() {
+		payloads[event.Name] = event.Attributes
+	}
+	assert.Equal(t, []attribute.KeyValue{
+		semantic.RPCPayloadKey.String(`{"user":{"name":"n","password":"***"}}`[:32]),
+		semantic.RPCPayloadTruncatedKey.Bool(true),
+	}, payloads[requestPayloadEventName])
+	assert.Equal(t, []attribute.KeyValue{
+		semantic.RPCPayloadKey.String(`"` + strings.Repeat("a", 31)),
+		semantic.RPCPayloadTruncatedKey.Bool(true),
+	}, payloads[responsePayloadEventName])
+}
+
+func TestClientTracerGenericCall(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	measure := &testutil.RecordingMeasure{}
+	cfg := NewConfig([]Option{WithMeasure(measure)})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	st := rpcinfo.NewRPCStats()
+	rpcinfo.AsMutableRPCStats(st).SetLevel(stats.LevelDetailed)
+	ri := rpcinfo.NewRPCInfo(
+		rpcinfo.NewEndpointInfo("caller", "CallerMethod", nil, nil),
+		rpcinfo.NewEndpointInfo("callee", "Echo", nil, nil),
+		rpcinfo.NewInvocation(serviceinfo.GenericService, serviceinfo.GenericMethod),
+		rpcinfo.NewRPCConfig(),
+		st,
+	)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	err := ClientMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		return nil
+	})(ctx, `{"message":"hello"}`, nil)
+	assert.Nil(t, err)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "callee/Echo", spans[0].Name())
+		assert.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+			semconv.RPCServiceKey.String("callee"),
+			semconv.RPCMethodKey.String("Echo"),
+			semantic.RPCGenericKey.Bool(true),
+			semantic.RPCGenericTypeKey.String("json"),
+		})
+	}
+	if assert.Len(t, measure.Labels[semantic.RPCCounter], 1) {
+		assert.Contains(t, measure.Labels[semantic.RPCCounter][0], label.CwLabel{Key: semantic.LabelRPCMethodKey, Value: "Echo"})
+	}
+}
+
+type carrierRequest struct {
+	Extra map[string]string
+}
+
+func TestRequestCarrier(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	carrier := WithRequestCarrier(func(req interface{}) propagation.TextMapCarrier {
+		r, ok := req.(*carrierRequest)
+		if !ok {
+			return nil
+		}
+		if r.Extra == nil {
+			r.Extra = map[string]string{}
+		}
+		return propagation.MapCarrier(r.Extra)
+	})
+	newTracer := func(kind trace.SpanKind) *KitexTracer {
+		cfg := NewConfig([]Option{WithMeasure(&testutil.RecordingMeasure{}), carrier, WithTextMapPropagator(propagation.TraceContext{})})
+		cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+		return &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: kind}
+	}
+
+	req := &carrierRequest{}
+	clientTracer := newTracer(trace.SpanKindClient)
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = clientTracer.Start(ctx)
+	err := ClientMiddleware(clientTracer.cfg)(func(ctx context.Context, req, resp interface{}) error {
+		return nil
+	})(ctx, req, nil)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, req.Extra["traceparent"])
+
+	// the server receives the request without any meta info
+	serverTracer := newTracer(trace.SpanKindServer)
+	sri := newTestRPCInfo(stats.LevelDetailed)
+	sctx := serverTracer.Start(rpcinfo.NewCtxWithRPCInfo(context.Background(), sri))
+	err = ServerMiddleware(serverTracer.cfg)(func(ctx context.Context, req, resp interface{}) error {
+		return nil
+	})(sctx, req, nil)
+	assert.Nil(t, err)
+	internal.TraceCarrierFromContext(sctx).Span().End()
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	clientTracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, spans[1].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+		assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	}
+}
+
+func TestServerMiddlewareGRPCMetadata(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg := NewConfig([]Option{
+		WithMeasure(&testutil.RecordingMeasure{}),
+		WithEnableGRPCMetadata(),
+		WithTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})),
+	})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindServer}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := tracer.Start(rpcinfo.NewCtxWithRPCInfo(context.Background(), ri))
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(
+		"traceparent", "00-01000000000000000000000000000000-0200000000000000-01",
+		"baggage", "tenant=t1",
+		string(semconv.ServiceNameKey), "grpc-go-client",
+	))
+	var bags baggage.Baggage
+	err := ServerMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		bags = baggage.FromContext(ctx)
+		return nil
+	})(ctx, nil, nil)
+	assert.Nil(t, err)
+	internal.TraceCarrierFromContext(ctx).Span().End()
+
+	assert.Equal(t, "t1", bags.Member("tenant").Value())
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "01000000000000000000000000000000", spans[0].SpanContext().TraceID().String())
+		assert.Equal(t, "0200000000000000", spans[0].Parent().SpanID().String())
+		assert.Contains(t, spans[0].Attributes(), semconv.PeerServiceKey.String("grpc-go-client"))
+	}
+}
+
+func TestServerBaggagePromotion(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	measure := &testutil.RecordingMeasure{}
+	cfg := NewConfig([]Option{
+		WithMeasure(measure),
+		WithTextMapPropagator(propagation.Baggage{}),
+		WithBaggageAttributes("tenant", "canary"),
+		WithBaggageMetricLabels("tenant", "canary"),
+	})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindServer}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	err := ServerMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		return nil
+	})(metainfo.WithValue(ctx, "baggage", "tenant=t1,user=u1"), nil, nil)
+	assert.Nil(t, err)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Contains(t, spans[0].Attributes(), attribute.String("tenant", "t1"))
+		for _, attr := range spans[0].Attributes() {
+			assert.NotEqual(t, attribute.Key("user"), attr.Key)
+			assert.NotEqual(t, attribute.Key("canary"), attr.Key)
+		}
+	}
+	if assert.Len(t, measure.Labels[semantic.RPCCounter], 1) {
+		assert.Subset(t, measure.Labels[semantic.RPCCounter][0], []label.CwLabel{
+			{Key: "tenant", Value: "t1"},
+			{Key: "canary", Value: semantic.UnknownLabelValue},
+		})
+	}
+}
+
+func TestClientTracerTransportMetrics(t *testing.T) {
+	measure := &testutil.RecordingMeasure{}
+	cfg := NewConfig([]Option{WithMeasure(measure)})
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	for _, event := range []stats.Event{
+		stats.ClientConnStart, stats.ClientConnFinish,
+		stats.WriteStart, stats.WriteFinish,
+		stats.WaitReadStart, stats.ReadStart, stats.ReadFinish,
+	} {
+		ri.Stats().Record(ctx, event, stats.StatusInfo, "")
+	}
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	assert.Len(t, measure.Records[semantic.RPCConnectDuration], 1)
+	assert.Len(t, measure.Records[semantic.RPCWriteDuration], 1)
+	assert.Len(t, measure.Records[semantic.RPCReadDuration], 1)
+	assert.Empty(t, measure.Records[semantic.RPCHandleDuration])
+}
+
+var testCustomEvent, _ = stats.DefineNewEvent("otelkitex_test_event", stats.LevelDetailed)
+
+func TestClientTracerStatsEvents(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg := NewConfig([]Option{
+		WithMeasure(&testutil.RecordingMeasure{}),
+		WithStatsEvents(map[string]stats.Event{"custom": testCustomEvent}),
+		WithStatsEventFilter(func(name string, event rpcinfo.Event) bool {
+			return name != "client_conn_finish"
+		}),
+	})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	for _, event := range []stats.Event{
+		stats.ClientConnStart, stats.ClientConnFinish, testCustomEvent,
+		stats.WriteStart, stats.WriteFinish, stats.ReadStart, stats.ReadFinish,
+	} {
+		ri.Stats().Record(ctx, event, stats.StatusInfo, "")
+	}
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		var names []string
+		for i, event := range spans[0].Events() {
+			names = append(names, event.Name)
+			if i > 0 {
+				assert.False(t, event.Time.Before(spans[0].Events()[i-1].Time))
+			}
+		}
+		assert.ElementsMatch(t, []string{"client_conn_start", "custom", "write_start", "write_finish", "read_start", "read_finish"}, names)
+	}
+}
+
+func TestServerTracerConcurrencyMetrics(t *testing.T) {
+	measure := &testutil.RecordingMeasure{}
+	cfg := NewConfig([]Option{WithMeasure(measure), WithEnableConcurrencyMetrics()})
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindServer}
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	ri.Stats().Record(ctx, stats.ReadStart, stats.StatusInfo, "")
+	ri.Stats().Record(ctx, stats.ReadFinish, stats.StatusInfo, "")
+	err := ServerMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		ri.Stats().Record(ctx, stats.ServerHandleStart, stats.StatusInfo, "")
+		assert.Equal(t, []float64{1}, measure.Records[semantic.RPCInFlight])
+		return nil
+	})(ctx, nil, nil)
+	assert.Nil(t, err)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+	tracer.QPSOverloadReport()
+
+	assert.Equal(t, []float64{1, -1}, measure.Records[semantic.RPCInFlight])
+	assert.Equal(t, measure.Labels[semantic.RPCInFlight][0], measure.Labels[semantic.RPCInFlight][1])
+	assert.Contains(t, measure.Labels[semantic.RPCInFlight][0], label.CwLabel{Key: semantic.LabelRPCMethodKey, Value: "Echo"})
+	assert.Len(t, measure.Records[semantic.RPCQueueWait], 1)
+	assert.Equal(t, [][]label.CwLabel{{{Key: semantic.LabelKeyLimit, Value: semantic.LimitQPS}}}, measure.Labels[semantic.RPCLimitRejection])
+}
+
+func TestServerSpanRPCAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg := NewConfig([]Option{WithMeasure(&testutil.RecordingMeasure{})})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindServer}
+
+	callerAddr, _ := net.ResolveTCPAddr("tcp", "10.0.0.1:5555")
+	calleeAddr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:8888")
+	st := rpcinfo.NewRPCStats()
+	rpcinfo.AsMutableRPCStats(st).SetLevel(stats.LevelDetailed)
+	ri := rpcinfo.NewRPCInfo(
+		rpcinfo.NewEndpointInfo("caller", "CallerMethod", callerAddr, nil),
+		rpcinfo.NewEndpointInfo("callee", "Echo", calleeAddr, nil),
+		rpcinfo.NewInvocation("EchoService", "Echo", "echo"),
+		rpcinfo.NewRPCConfig(),
+		st,
+	)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	err := ServerMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		return nil
+	})(ctx, nil, nil)
+	assert.Nil(t, err)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+			semantic.RPCSystemKitex,
+			semconv.RPCServiceKey.String("EchoService"),
+			semconv.RPCMethodKey.String("Echo"),
+			semantic.RPCPayloadCodecKey.String("thrift"),
+			semconv.NetTransportTCP,
+			semconv.NetPeerIPKey.String("10.0.0.1"),
+			semconv.NetPeerPortKey.Int(5555),
+			semconv.NetHostIPKey.String("127.0.0.1"),
+			semconv.NetHostPortKey.Int(8888),
+		})
+	}
+}
+
+func TestClientOneWayRootSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg := NewConfig([]Option{WithMeasure(&testutil.RecordingMeasure{}), WithOneWayRootSpans(nil)})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	ctx, parent := cfg.tracer.Start(context.Background(), "parent")
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	assert.Nil(t, rpcinfo.AsMutableRPCConfig(ri.Config()).SetInteractionMode(rpcinfo.Oneway))
+	ctx = rpcinfo.NewCtxWithRPCInfo(ctx, ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+	parent.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.False(t, spans[0].Parent().IsValid())
+		assert.NotEqual(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+		if assert.Len(t, spans[0].Links(), 1) {
+			assert.Equal(t, parent.SpanContext(), spans[0].Links()[0].SpanContext)
+		}
+	}
+}
+
+func TestDetachContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg := NewConfig([]Option{WithMeasure(&testutil.RecordingMeasure{})})
+	cfg.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(instrumentationName)
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindClient}
+
+	parentCtx, parent := cfg.tracer.Start(context.Background(), "parent")
+	parentCtx = metainfo.WithPersistentValue(parentCtx, "tenant", "a")
+	parentCtx, cancel := context.WithCancel(parentCtx)
+	detached := DetachContext(parentCtx)
+	cancel()
+	parent.End()
+
+	assert.Nil(t, detached.Err())
+	assert.False(t, trace.SpanContextFromContext(detached).IsValid())
+	v, ok := metainfo.GetPersistentValue(detached, "tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+
+	ri := newTestRPCInfo(stats.LevelDetailed)
+	ctx := rpcinfo.NewCtxWithRPCInfo(detached, ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) && assert.Len(t, spans[1].Links(), 1) {
+		assert.Equal(t, parent.SpanContext(), spans[1].Links()[0].SpanContext)
+	}
+}
+
+func TestServerCallerPeerLabels(t *testing.T) {
+	measure := &testutil.RecordingMeasure{}
+	cfg := NewConfig([]Option{WithMeasure(measure), WithCallerPeerLabels()})
+	tracer := &KitexTracer{measure: cfg.measure, cfg: cfg, spanKind: trace.SpanKindServer}
+
+	// the client has no basic info
+	st := rpcinfo.NewRPCStats()
+	rpcinfo.AsMutableRPCStats(st).SetLevel(stats.LevelDetailed)
+	ri := rpcinfo.NewRPCInfo(
+		rpcinfo.NewEndpointInfo("", "", nil, nil),
+		rpcinfo.NewEndpointInfo("callee", "Echo", nil, nil),
+		rpcinfo.NewInvocation("EchoService", "Echo", "echo"),
+		rpcinfo.NewRPCConfig(),
+		st,
+	)
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	ri.Stats().Record(ctx, stats.RPCStart, stats.StatusInfo, "")
+	ctx = tracer.Start(ctx)
+	callerCtx := metainfo.WithValue(ctx, string(semconv.ServiceNameKey), "caller")
+	callerCtx = metainfo.WithValue(callerCtx, string(semconv.ServiceNamespaceKey), "ns")
+	err := ServerMiddleware(cfg)(func(ctx context.Context, req, resp interface{}) error {
+		return nil
+	})(callerCtx, nil, nil)
+	assert.Nil(t, err)
+	ri.Stats().Record(ctx, stats.RPCFinish, stats.StatusInfo, "")
+	tracer.Finish(ctx)
+
+	if assert.Len(t, measure.Labels[semantic.RPCCounter], 1) {
+		assert.Subset(t, measure.Labels[semantic.RPCCounter][0], []label.CwLabel{
+			{Key: semantic.LabelRPCCallerKey, Value: "caller"},
+			{Key: semantic.LabelRPCCallerNamespaceKey, Value: "ns"},
+			{Key: semantic.LabelRPCCallerEnvironmentKey, Value: semantic.UnknownLabelValue},
+		})
+	}
+}
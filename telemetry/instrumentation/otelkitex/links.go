@@ -0,0 +1,74 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+
+	"github.com/bytedance/gopkg/cloud/metainfo"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type spanLinksContextKeyType struct{}
+
+var spanLinksContextKey spanLinksContextKeyType
+
+// ContextWithLinks returns a copy of ctx whose client spans are linked to links
+func ContextWithLinks(ctx context.Context, links ...trace.Link) context.Context {
+	return context.WithValue(ctx, spanLinksContextKey, append(linksFromContext(ctx), links...))
+}
+
+func linksFromContext(ctx context.Context) []trace.Link {
+	if links, ok := ctx.Value(spanLinksContextKey).([]trace.Link); ok {
+		return links[:len(links):len(links)]
+	}
+	return nil
+}
+
+// DetachContext captures the span context of ctx for a goroutine outliving the call. The returned
+// context is not canceled with ctx and keeps its baggage and persistent meta info, its client spans
+// are new root spans linked to the span of ctx.
+func DetachContext(ctx context.Context) context.Context {
+	detached := baggage.ContextWithBaggage(context.Background(), baggage.FromContext(ctx))
+	for k, v := range metainfo.GetAllPersistentValues(ctx) {
+		detached = metainfo.WithPersistentValue(detached, k, v)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		detached = ContextWithLinks(detached, trace.Link{SpanContext: sc})
+	}
+	return detached
+}
+
+// isOneWayCall reports whether the call is a one-way call, the servers do not flag them in the rpc info
+func isOneWayCall(cfg *Config, ri rpcinfo.RPCInfo) bool {
+	if ri.Config() != nil && ri.Config().InteractionMode() == rpcinfo.Oneway {
+		return true
+	}
+	return cfg.isOneWay != nil && cfg.isOneWay(ri)
+}
+
+// oneWayRootSpanOptions starts the span of a one-way call as a new root span linked to the caller span
+func oneWayRootSpanOptions(cfg *Config, ri rpcinfo.RPCInfo, caller trace.SpanContext) []trace.SpanStartOption {
+	if !cfg.oneWayRootSpans || !isOneWayCall(cfg, ri) {
+		return nil
+	}
+	opts := []trace.SpanStartOption{trace.WithNewRoot()}
+	if caller.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: caller}))
+	}
+	return opts
+}
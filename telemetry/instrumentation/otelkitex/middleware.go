@@ -27,26 +27,45 @@ import (
 	"github.com/cloudwego/kitex/pkg/endpoint"
 	"github.com/cloudwego/kitex/pkg/rpcinfo"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/sdk/trace"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-// ClientMiddleware inject span context into req meta
+// ClientMiddleware inject the span context of the client span started by the client tracer into req meta
 func ClientMiddleware(cfg *Config) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, req, resp interface{}) (err error) {
+			setGenericSpanAttributes(ctx, req)
+			recordPayloadEvent(ctx, cfg, requestPayloadEventName, requestOf(req))
+			defer func() {
+				setCustomSpanAttributes(ctx, cfg, req, resp)
+				recordPayloadEvent(ctx, cfg, responsePayloadEventName, responseOf(resp))
+			}()
+
+			// the middleware runs once per attempt when the call is retried
+			if cfg.enableRetryAttempts {
+				var finishAttempt func(err error)
+				ctx, finishAttempt = startAttempt(ctx, cfg)
+				defer func() { finishAttempt(err) }()
+			}
+
 			span := oteltrace.SpanFromContext(ctx)
 			if !span.IsRecording() {
 				return next(ctx, req, resp)
 			}
 
-			readOnlySpan := span.(trace.ReadOnlySpan)
-
-			// inject client service resource attributes (canonical service) to meta info
-			md := injectPeerServiceToMetaInfo(ctx, readOnlySpan.Resource().Attributes())
+			// inject client service resource attributes (canonical service) to meta info,
+			// the resource is only known for spans of the otel sdk
+			var resourceAttrs []attribute.KeyValue
+			if readOnlySpan, ok := span.(trace.ReadOnlySpan); ok {
+				resourceAttrs = readOnlySpan.Resource().Attributes()
+			}
+			md := injectPeerServiceToMetaInfo(ctx, resourceAttrs)
 
 			Inject(ctx, cfg, md)
+			injectToRequest(ctx, cfg, req)
 
 			if cfg.enableGRPCMetadata {
 				grpcMd, ok := metadata.FromOutgoingContext(ctx)
@@ -68,6 +87,8 @@ func ClientMiddleware(cfg *Config) endpoint.Middleware {
 func ServerMiddleware(cfg *Config) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, req, resp interface{}) (err error) {
+			startInFlight(ctx, cfg)
+
 			tc := internal.TraceCarrierFromContext(ctx)
 			if tc == nil {
 				klog.CtxWarnf(ctx, "TraceCarrier not found in context")
@@ -81,29 +102,56 @@ func ServerMiddleware(cfg *Config) endpoint.Middleware {
 			opts := []oteltrace.SpanStartOption{
 				oteltrace.WithTimestamp(getStartTimeOrNow(ri)),
 				oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+				oteltrace.WithAttributes(rpcSpanAttributes(ri, oteltrace.SpanKindServer)...),
 			}
 
 			md := metainfo.GetAllValues(ctx)
 			peerServiceAttributes := extractPeerServiceAttributesFromMetaInfo(md)
 
+			bags, spanCtx := Extract(ctx, cfg, md)
+
+			// gRPC clients like grpc-go propagate in the incoming metadata instead of the meta info
 			if cfg.enableGRPCMetadata {
-				grpcMd, ok := metadata.FromOutgoingContext(ctx)
-				if ok {
-					ctx = injectMetadata(ctx, cfg, grpcMd)
+				if grpcMd, ok := metadata.FromIncomingContext(ctx); ok {
+					mdCtx := extractMetadata(ctx, cfg, grpcMd)
+					if !spanCtx.IsValid() {
+						spanCtx = oteltrace.SpanContextFromContext(mdCtx)
+					}
+					if bags.Len() == 0 {
+						bags = baggage.FromContext(mdCtx)
+					}
+					if len(peerServiceAttributes) == 0 {
+						peerServiceAttributes = extractPeerServiceAttributesFromMetadata(grpcMd)
+					}
 				}
 			}
-
-			bags, spanCtx := Extract(ctx, cfg, md)
+			bags, spanCtx = extractFromRequest(ctx, cfg, req, bags, spanCtx)
 			ctx = baggage.ContextWithBaggage(ctx, bags)
+			tc.SetBaggage(bags)
+			tc.SetPeerService(peerServiceAttributes)
 
-			ctx, span := sTracer.Start(oteltrace.ContextWithRemoteSpanContext(ctx, spanCtx), spanNaming(ri), opts...)
+			// keep propagating the caller trace to the downstream calls of ignored methods
+			if cfg.shouldIgnoreTracing(ri) {
+				return next(oteltrace.ContextWithRemoteSpanContext(ctx, spanCtx), req, resp)
+			}
+
+			opts = append(opts, oneWayRootSpanOptions(cfg, ri, spanCtx)...)
+			ctx, span := sTracer.Start(oteltrace.ContextWithRemoteSpanContext(ctx, spanCtx), cfg.spanNameFormatter(ri), opts...)
 
 			// peer service attributes
 			span.SetAttributes(peerServiceAttributes...)
+			span.SetAttributes(internal.BaggageAttributes(bags, cfg.baggageAttributeKeys)...)
 
 			// set span and attrs into tracer carrier for serverTracer finish
 			tc.SetSpan(span)
 
+			setGenericSpanAttributes(ctx, req)
+			recordPayloadEvent(ctx, cfg, requestPayloadEventName, requestOf(req))
+			defer func() {
+				setCustomSpanAttributes(ctx, cfg, req, resp)
+				recordPayloadEvent(ctx, cfg, responsePayloadEventName, responseOf(resp))
+			}()
+
 			return next(ctx, req, resp)
 		}
 	}
@@ -20,7 +20,9 @@ import (
 	cwmetric "github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/metric"
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
 	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/stats"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
@@ -30,6 +32,14 @@ const (
 	instrumentationName = "github.com/otelkitex-contrib/telemetry-opentelemetry"
 )
 
+// ConditionFunc decides per call, the method of a server call is only known once the request is decoded
+type ConditionFunc func(ri rpcinfo.RPCInfo) bool
+
+// SpanAttributesFunc returns the attributes added to the span of a call once it is handled,
+// req and resp are the request and response of the method when they can be unwrapped
+// from the kitex args and result, the kitex args and result otherwise
+type SpanAttributesFunc func(ri rpcinfo.RPCInfo, req, resp interface{}) []attribute.KeyValue
+
 // Option opts for opentelemetry tracer provider
 type Option interface {
 	apply(cfg *Config)
@@ -49,8 +59,36 @@ type Config struct {
 	meterProvider     metric.MeterProvider
 	textMapPropagator propagation.TextMapPropagator
 
-	recordSourceOperation bool
-	enableGRPCMetadata    bool
+	recordSourceOperation    bool
+	enableGRPCMetadata       bool
+	enableRetryAttempts      bool
+	enableConcurrencyMetrics bool
+
+	errorClassifier ErrorClassifier
+
+	shouldIgnoreTracing ConditionFunc
+	shouldIgnoreMetrics ConditionFunc
+
+	spanNameFormatter  func(ri rpcinfo.RPCInfo) string
+	spanAttributesFunc SpanAttributesFunc
+
+	requestCarrier RequestCarrier
+
+	oneWayRootSpans bool
+	isOneWay        ConditionFunc
+
+	statsEvents      map[string]stats.Event
+	statsEventFilter StatsEventFilter
+
+	baggageAttributeKeys []string
+	baggageLabelKeys     []string
+
+	callerPeerLabels bool
+
+	capturePayload    bool
+	payloadMaxSize    int
+	payloadSerializer PayloadSerializer
+	payloadRedactor   PayloadRedactor
 
 	measure cwmetric.Measure
 }
@@ -75,6 +113,16 @@ func DefaultConfig() *Config {
 		meterProvider:     otel.GetMeterProvider(),
 		textMapPropagator: otel.GetTextMapPropagator(),
 		measure:           global.GetTracerMeasure(),
+		errorClassifier:   DefaultErrorClassifier,
+		shouldIgnoreTracing: func(ri rpcinfo.RPCInfo) bool {
+			return false
+		},
+		shouldIgnoreMetrics: func(ri rpcinfo.RPCInfo) bool {
+			return false
+		},
+		spanNameFormatter: spanNaming,
+		payloadMaxSize:    defaultPayloadMaxSize,
+		payloadSerializer: DefaultPayloadSerializer,
 	}
 }
 
@@ -109,8 +157,171 @@ func WithLabelFunc(labelFunc func(info rpcinfo.RPCInfo) []label.CwLabel) Option
 	})
 }
 
+// WithEnableGRPCMetadata propagates the trace context in the gRPC metadata as well, the clients inject
+// it into the outgoing metadata and the servers extract it from the incoming metadata
 func WithEnableGRPCMetadata() Option {
 	return option(func(cfg *Config) {
 		cfg.enableGRPCMetadata = true
 	})
 }
+
+// WithEnableRetryAttempts records every attempt of the client calls as a child span of the call,
+// with its attempt number and backup request flag, and counts the outcome of every attempt
+func WithEnableRetryAttempts() Option {
+	return option(func(cfg *Config) {
+		cfg.enableRetryAttempts = true
+	})
+}
+
+// WithEnableConcurrencyMetrics records the queue wait of the server calls and, once the tracer is
+// registered with server.WithLimitReporter, the requests rejected by the server limiter
+func WithEnableConcurrencyMetrics() Option {
+	return option(func(cfg *Config) {
+		cfg.enableConcurrencyMetrics = true
+	})
+}
+
+// WithErrorClassifier configures the classifier of the error_type label and error.type span attribute
+func WithErrorClassifier(classifier ErrorClassifier) Option {
+	return option(func(cfg *Config) {
+		if classifier != nil {
+			cfg.errorClassifier = classifier
+		}
+	})
+}
+
+// WithShouldIgnore skips both the tracing and the metrics of the calls matching the condition,
+// like health check probes
+func WithShouldIgnore(condition ConditionFunc) Option {
+	return option(func(cfg *Config) {
+		if condition != nil {
+			cfg.shouldIgnoreTracing = condition
+			cfg.shouldIgnoreMetrics = condition
+		}
+	})
+}
+
+// WithShouldIgnoreTracing skips the spans of the calls matching the condition,
+// the trace context is still propagated to the downstream calls
+func WithShouldIgnoreTracing(condition ConditionFunc) Option {
+	return option(func(cfg *Config) {
+		if condition != nil {
+			cfg.shouldIgnoreTracing = condition
+		}
+	})
+}
+
+// WithShouldIgnoreMetrics skips the metrics of the calls matching the condition
+func WithShouldIgnoreMetrics(condition ConditionFunc) Option {
+	return option(func(cfg *Config) {
+		if condition != nil {
+			cfg.shouldIgnoreMetrics = condition
+		}
+	})
+}
+
+// WithSpanNameFormatter configures the span name of the calls, $package.$service/$method by default
+func WithSpanNameFormatter(formatter func(ri rpcinfo.RPCInfo) string) Option {
+	return option(func(cfg *Config) {
+		if formatter != nil {
+			cfg.spanNameFormatter = formatter
+		}
+	})
+}
+
+// WithSpanAttributesFunc adds custom attributes like tenant ids or request fields to the span of the calls
+func WithSpanAttributesFunc(fn SpanAttributesFunc) Option {
+	return option(func(cfg *Config) {
+		cfg.spanAttributesFunc = fn
+	})
+}
+
+// WithRequestCarrier propagates the trace context inside the requests as well,
+// for the transports which cannot carry it in headers like Framed and Buffered Thrift
+func WithRequestCarrier(carrier RequestCarrier) Option {
+	return option(func(cfg *Config) {
+		cfg.requestCarrier = carrier
+	})
+}
+
+// WithOneWayRootSpans starts the spans of the one-way calls as new root spans linked to the span
+// of the caller. The client flags the one-way calls, the servers do not so isOneWay tells them
+// the one-way methods, it can be nil for the clients.
+func WithOneWayRootSpans(isOneWay ConditionFunc) Option {
+	return option(func(cfg *Config) {
+		cfg.oneWayRootSpans = true
+		cfg.isOneWay = isOneWay
+	})
+}
+
+// WithStatsEvents adds user defined stats events to the span events, the key is the name of the span event
+func WithStatsEvents(events map[string]stats.Event) Option {
+	return option(func(cfg *Config) {
+		if cfg.statsEvents == nil {
+			cfg.statsEvents = make(map[string]stats.Event, len(events))
+		}
+		for name, event := range events {
+			cfg.statsEvents[name] = event
+		}
+	})
+}
+
+// WithStatsEventFilter configures which stats events are added to the span events
+func WithStatsEventFilter(filter StatsEventFilter) Option {
+	return option(func(cfg *Config) {
+		cfg.statsEventFilter = filter
+	})
+}
+
+// WithBaggageAttributes copies the members of the baggage in keys onto the server spans
+func WithBaggageAttributes(keys ...string) Option {
+	return option(func(cfg *Config) {
+		cfg.baggageAttributeKeys = keys
+	})
+}
+
+// WithBaggageMetricLabels adds the members of the baggage in keys to the metric labels,
+// keep the keys to low cardinality members like tenant or canary. The characters which are not
+// valid in prometheus label names are replaced by underscores, tenant.id is labelled tenant_id.
+// Declare the labels with promprovider.WithRPCExtraLabels, the prometheus metrics are not recorded otherwise
+func WithBaggageMetricLabels(keys ...string) Option {
+	return option(func(cfg *Config) {
+		cfg.baggageLabelKeys = keys
+	})
+}
+
+// WithCallerPeerLabels adds the namespace and the deployment environment propagated by the callers
+// to the metric labels of the servers, declare them with promprovider.WithRPCExtraLabels
+func WithCallerPeerLabels() Option {
+	return option(func(cfg *Config) {
+		cfg.callerPeerLabels = true
+	})
+}
+
+// WithPayloadCapture records the requests and responses as span events, truncated to maxSize bytes
+// (4096 when maxSize is not positive). It is meant for debugging and is disabled by default.
+func WithPayloadCapture(maxSize int) Option {
+	return option(func(cfg *Config) {
+		cfg.capturePayload = true
+		if maxSize > 0 {
+			cfg.payloadMaxSize = maxSize
+		}
+	})
+}
+
+// WithPayloadSerializer configures the serializer of the captured payloads, JSON by default
+func WithPayloadSerializer(serializer PayloadSerializer) Option {
+	return option(func(cfg *Config) {
+		if serializer != nil {
+			cfg.payloadSerializer = serializer
+		}
+	})
+}
+
+// WithPayloadRedactor configures the redaction of the fields of the captured payloads,
+// payloads which are not serialized to JSON are not recorded when a redactor is configured
+func WithPayloadRedactor(redactor PayloadRedactor) Option {
+	return option(func(cfg *Config) {
+		cfg.payloadRedactor = redactor
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/internal"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	requestPayloadEventName  = "request.payload"
+	responsePayloadEventName = "response.payload"
+
+	defaultPayloadMaxSize = 4096
+)
+
+var errPayloadNotJSON = errors.New("payload is not a JSON document and cannot be redacted")
+
+// PayloadSerializer serializes the request or response of a call for the payload capture
+type PayloadSerializer func(ri rpcinfo.RPCInfo, payload interface{}) ([]byte, error)
+
+// PayloadRedactor returns the value recorded for a field of the serialized JSON payload,
+// field is the dotted path of the field like "user.password"
+type PayloadRedactor func(ri rpcinfo.RPCInfo, field string, value interface{}) interface{}
+
+// DefaultPayloadSerializer serializes the payload to JSON, the JSON strings of the generic calls are kept as is
+func DefaultPayloadSerializer(ri rpcinfo.RPCInfo, payload interface{}) ([]byte, error) {
+	switch p := payload.(type) {
+	case string:
+		if json.Valid([]byte(p)) {
+			return []byte(p), nil
+		}
+	case []byte:
+		if json.Valid(p) {
+			return p, nil
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// requestOf returns the request of the kitex args
+func requestOf(req interface{}) interface{} {
+	if args, ok := req.(utils.KitexArgs); ok {
+		return args.GetFirstArgument()
+	}
+	return req
+}
+
+// responseOf returns the response of the kitex result
+func responseOf(resp interface{}) interface{} {
+	if result, ok := resp.(utils.KitexResult); ok {
+		return result.GetResult()
+	}
+	return resp
+}
+
+// recordPayloadEvent records the serialized payload as an event of the span of the call
+func recordPayloadEvent(ctx context.Context, cfg *Config, name string, payload interface{}) {
+	if !cfg.capturePayload {
+		return
+	}
+	tc := internal.TraceCarrierFromContext(ctx)
+	if tc == nil || tc.Span() == nil || !tc.Span().IsRecording() {
+		return
+	}
+
+	ri := rpcinfo.GetRPCInfo(ctx)
+	data, err := cfg.payloadSerializer(ri, payload)
+	if err == nil && cfg.payloadRedactor != nil {
+		data, err = redactPayload(ri, data, cfg.payloadRedactor)
+	}
+	if err != nil {
+		tc.Span().AddEvent(name, trace.WithAttributes(attribute.String("event.info", err.Error())))
+		return
+	}
+
+	truncated := len(data) > cfg.payloadMaxSize
+	if truncated {
+		data = data[:cfg.payloadMaxSize]
+	}
+	tc.Span().AddEvent(name, trace.WithAttributes(
+		semantic.RPCPayloadKey.String(strings.ToValidUTF8(string(data), "")),
+		semantic.RPCPayloadTruncatedKey.Bool(truncated),
+	))
+}
+
+// redactPayload applies the redactor to every field of the JSON payload
+func redactPayload(ri rpcinfo.RPCInfo, data []byte, redactor PayloadRedactor) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errPayloadNotJSON
+	}
+	return json.Marshal(redactValue(ri, "", doc, redactor))
+}
+
+func redactValue(ri rpcinfo.RPCInfo, path string, value interface{}, redactor PayloadRedactor) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, fieldValue := range v {
+			fieldPath := field
+			if path != "" {
+				fieldPath = path + "." + field
+			}
+			v[field] = redactValue(ri, fieldPath, redactor(ri, fieldPath, fieldValue), redactor)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(ri, path, item, redactor)
+		}
+	}
+	return value
+}
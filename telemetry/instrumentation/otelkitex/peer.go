@@ -17,6 +17,7 @@ package otelkitex
 import (
 	"context"
 
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
 	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/metadata"
 
@@ -81,3 +82,27 @@ func extractPeerServiceAttributesFromMetadata(md metadata.MD) []attribute.KeyVal
 	}
 	return attrs
 }
+
+// peerServiceValue returns the value of key in the peer service attributes propagated by the caller
+func peerServiceValue(attrs []attribute.KeyValue, key attribute.Key) string {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+// callerPeerLabels returns the namespace and deployment environment labels of the caller
+func callerPeerLabels(attrs []attribute.KeyValue) []label.CwLabel {
+	return []label.CwLabel{
+		{
+			Key:   semantic.LabelRPCCallerNamespaceKey,
+			Value: defaultValIfEmpty(peerServiceValue(attrs, semantic.PeerServiceNamespaceKey), semantic.UnknownLabelValue),
+		},
+		{
+			Key:   semantic.LabelRPCCallerEnvironmentKey,
+			Value: defaultValIfEmpty(peerServiceValue(attrs, semantic.PeerDeploymentEnvironmentKey), semantic.UnknownLabelValue),
+		},
+	}
+}
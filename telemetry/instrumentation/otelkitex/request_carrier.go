@@ -0,0 +1,71 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestCarrier returns the carrier of the trace context embedded in the request, like a map field
+// of the Thrift request. It returns nil when the request cannot carry the trace context.
+//
+// The client calls it to inject the trace context and the server to extract it, so it allows the
+// propagation over the transports without headers like Framed and Buffered Thrift:
+//
+//	func(req interface{}) propagation.TextMapCarrier {
+//		r, ok := req.(*echo.Request)
+//		if !ok {
+//			return nil
+//		}
+//		if r.Base == nil {
+//			r.Base = base.NewBase()
+//		}
+//		if r.Base.Extra == nil {
+//			r.Base.Extra = map[string]string{}
+//		}
+//		return propagation.MapCarrier(r.Base.Extra)
+//	}
+type RequestCarrier func(req interface{}) propagation.TextMapCarrier
+
+// injectToRequest injects the trace context into the carrier of the request
+func injectToRequest(ctx context.Context, cfg *Config, req interface{}) {
+	if cfg.requestCarrier == nil {
+		return
+	}
+	if carrier := cfg.requestCarrier(requestOf(req)); carrier != nil {
+		cfg.textMapPropagator.Inject(ctx, carrier)
+	}
+}
+
+// extractFromRequest extracts the trace context from the carrier of the request,
+// it is only used when the trace context was not propagated through the transport
+func extractFromRequest(ctx context.Context, cfg *Config, req interface{}, bags baggage.Baggage, spanCtx trace.SpanContext) (baggage.Baggage, trace.SpanContext) {
+	if cfg.requestCarrier == nil || spanCtx.IsValid() {
+		return bags, spanCtx
+	}
+	carrier := cfg.requestCarrier(requestOf(req))
+	if carrier == nil {
+		return bags, spanCtx
+	}
+	ctx = cfg.textMapPropagator.Extract(ctx, carrier)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return baggage.FromContext(ctx), sc
+	}
+	return bags, spanCtx
+}
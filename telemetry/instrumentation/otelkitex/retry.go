@@ -0,0 +1,110 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type retryStatsContextKeyType struct{}
+
+var retryStatsContextKey retryStatsContextKeyType
+
+// retryStats tracks the attempts of a client call in flight, kitex does not flag backup requests
+// so an attempt started while a previous one is still running is a backup request
+type retryStats struct {
+	inFlight atomic.Int32
+}
+
+func withRetryStats(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryStatsContextKey, &retryStats{})
+}
+
+func retryStatsFromContext(ctx context.Context) *retryStats {
+	if s, ok := ctx.Value(retryStatsContextKey).(*retryStats); ok {
+		return s
+	}
+	return nil
+}
+
+// startAttempt starts the child span of an attempt of the call, the returned func ends it
+// and counts the outcome of the attempt
+func startAttempt(ctx context.Context, cfg *Config) (context.Context, func(err error)) {
+	ri := rpcinfo.GetRPCInfo(ctx)
+	if ri == nil {
+		return ctx, func(error) {}
+	}
+
+	// kitex tags the callee of every retry with its number
+	attempt := 0
+	if retryTimes, ok := ri.To().Tag(rpcinfo.RetryTag); ok {
+		attempt, _ = strconv.Atoi(retryTimes)
+	}
+
+	rs := retryStatsFromContext(ctx)
+	backup := false
+	if rs != nil {
+		backup = rs.inFlight.Add(1) > 1 && attempt > 0
+	}
+
+	var span trace.Span
+	if trace.SpanFromContext(ctx).IsRecording() && cfg.tracer != nil && !cfg.shouldIgnoreTracing(ri) {
+		ctx, span = cfg.tracer.Start(ctx, cfg.spanNameFormatter(ri)+" attempt",
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(
+				semantic.RPCRetryAttemptKey.Int(attempt),
+				semantic.RPCBackupRequestKey.Bool(backup),
+			),
+		)
+	}
+
+	return ctx, func(err error) {
+		if rs != nil {
+			rs.inFlight.Add(-1)
+		}
+
+		status := semantic.StatusSucceed
+		if err != nil {
+			status = semantic.StatusError
+		}
+
+		if span != nil {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+
+		if cfg.measure != nil && !cfg.shouldIgnoreMetrics(ri) {
+			cfg.measure.Inc(ctx, semantic.RPCRetryAttempt,
+				label.CwLabel{Key: semantic.LabelRPCCallerKey, Value: defaultValIfEmpty(ri.From().ServiceName(), semantic.UnknownLabelValue)},
+				label.CwLabel{Key: semantic.LabelRPCCalleeKey, Value: defaultValIfEmpty(ri.To().ServiceName(), semantic.UnknownLabelValue)},
+				label.CwLabel{Key: semantic.LabelRPCMethodKey, Value: defaultValIfEmpty(rpcMethodName(ri), semantic.UnknownLabelValue)},
+				label.CwLabel{Key: semantic.LabelKeyAttempt, Value: strconv.Itoa(attempt)},
+				label.CwLabel{Key: semantic.LabelKeyBackup, Value: strconv.FormatBool(backup)},
+				label.CwLabel{Key: semantic.LabelKeyStatus, Value: status},
+			)
+		}
+	}
+}
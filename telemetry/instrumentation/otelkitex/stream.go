@@ -0,0 +1,156 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelkitex
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/internal"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+	"github.com/cloudwego/kitex/pkg/endpoint"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/stats"
+	"github.com/cloudwego/kitex/pkg/streaming"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ rpcinfo.StreamEventReporter = (*KitexTracer)(nil)
+
+const messageEventName = "message"
+
+type streamStatsContextKeyType struct{}
+
+var streamStatsContextKey streamStatsContextKeyType
+
+// streamStats counts the messages of a stream, sizes are only known when the stream middlewares are used
+type streamStats struct {
+	sent     atomic.Int64
+	received atomic.Int64
+
+	lastSentSize     atomic.Int64
+	lastReceivedSize atomic.Int64
+}
+
+func newStreamStats() *streamStats {
+	s := &streamStats{}
+	s.lastSentSize.Store(-1)
+	s.lastReceivedSize.Store(-1)
+	return s
+}
+
+func withStreamStats(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamStatsContextKey, newStreamStats())
+}
+
+func streamStatsFromContext(ctx context.Context) *streamStats {
+	if s, ok := ctx.Value(streamStatsContextKey).(*streamStats); ok {
+		return s
+	}
+	return nil
+}
+
+// ReportStreamEvent record a message sent or received on a stream as a span event.
+func (s *KitexTracer) ReportStreamEvent(ctx context.Context, ri rpcinfo.RPCInfo, event rpcinfo.Event) {
+	ss := streamStatsFromContext(ctx)
+	if ss == nil {
+		return
+	}
+
+	var (
+		attrs []attribute.KeyValue
+		id    int64
+		size  int64
+	)
+	switch event.Event() {
+	case stats.StreamSend:
+		id, size = ss.sent.Add(1), ss.lastSentSize.Swap(-1)
+		attrs = append(attrs, semconv.MessageTypeSent)
+	case stats.StreamRecv:
+		id, size = ss.received.Add(1), ss.lastReceivedSize.Swap(-1)
+		attrs = append(attrs, semconv.MessageTypeReceived)
+	default:
+		return
+	}
+
+	tc := internal.TraceCarrierFromContext(ctx)
+	if tc == nil || tc.Span() == nil || !tc.Span().IsRecording() {
+		return
+	}
+
+	attrs = append(attrs, semconv.MessageIDKey.Int64(id))
+	if size >= 0 {
+		attrs = append(attrs, semconv.MessageUncompressedSizeKey.Int64(size))
+	}
+	if event.Status() == stats.StatusError {
+		attrs = append(attrs, attribute.String("event.info", event.Info()))
+	}
+
+	tc.Span().AddEvent(messageEventName, trace.WithTimestamp(event.Time()), trace.WithAttributes(attrs...))
+}
+
+// recordStreamMetrics record the messages per rpc and the duration of a streaming call,
+// the server does not flag streaming calls so they are detected by their stream events
+func (s *KitexTracer) recordStreamMetrics(ctx context.Context, ri rpcinfo.RPCInfo, elapsedTime float64, labels []label.CwLabel) {
+	ss := streamStatsFromContext(ctx)
+	if ss == nil {
+		return
+	}
+	sent, received := ss.sent.Load(), ss.received.Load()
+	if ri.Config().InteractionMode() != rpcinfo.Streaming && sent+received == 0 {
+		return
+	}
+
+	requests, responses := received, sent
+	if s.spanKind == trace.SpanKindClient {
+		requests, responses = sent, received
+	}
+
+	s.measure.Record(ctx, semantic.RPCRequestsPerRPC, float64(requests), labels...)
+	s.measure.Record(ctx, semantic.RPCResponsesPerRPC, float64(responses), labels...)
+	s.measure.Record(ctx, semantic.RPCStreamDuration, elapsedTime, labels...)
+}
+
+// StreamRecvMiddleware records the size of the received messages on the message span events,
+// the size is known for messages implementing Size() int like fastpb and gogo protobuf messages.
+func StreamRecvMiddleware(next endpoint.RecvEndpoint) endpoint.RecvEndpoint {
+	return func(stream streaming.Stream, message interface{}) error {
+		err := next(stream, message)
+		if ss := streamStatsFromContext(stream.Context()); ss != nil && err == nil {
+			ss.lastReceivedSize.Store(messageSize(message))
+		}
+		return err
+	}
+}
+
+// StreamSendMiddleware records the size of the sent messages on the message span events
+func StreamSendMiddleware(next endpoint.SendEndpoint) endpoint.SendEndpoint {
+	return func(stream streaming.Stream, message interface{}) error {
+		if ss := streamStatsFromContext(stream.Context()); ss != nil {
+			ss.lastSentSize.Store(messageSize(message))
+		}
+		return next(stream, message)
+	}
+}
+
+func messageSize(message interface{}) int64 {
+	if sizer, ok := message.(interface{ Size() int }); ok {
+		return int64(sizer.Size())
+	}
+	return -1
+}
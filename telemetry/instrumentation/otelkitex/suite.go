@@ -16,6 +16,7 @@ package otelkitex
 
 import (
 	"github.com/cloudwego/kitex/client"
+	"github.com/cloudwego/kitex/client/streamclient"
 	"github.com/cloudwego/kitex/pkg/transmeta"
 	"github.com/cloudwego/kitex/server"
 	"github.com/cloudwego/kitex/transport"
@@ -52,18 +53,25 @@ func NewClientSuite(opts ...Option) *clientSuite {
 		client.WithMetaHandler(transmeta.ClientHTTP2Handler),
 		client.WithMetaHandler(transmeta.ClientTTHeaderHandler),
 	}
+	cOpts = append(cOpts, clientStreamOptions()...)
 	return &clientSuite{cOpts}
 }
 
 // NewServerSuite server suite for otel with http2 and ttheader meta handler
 func NewServerSuite(opts ...Option) *serverSuite {
-	serverOpts, cfg := NewServerOption(opts...)
+	kitexTracer := NewServerTracer(opts...)
+	cfg := kitexTracer.cfg
 	sOpts := []server.Option{
-		serverOpts,
+		server.WithTracer(kitexTracer),
 		server.WithMiddleware(ServerMiddleware(cfg)),
+		server.WithRecvMiddleware(StreamRecvMiddleware),
+		server.WithSendMiddleware(StreamSendMiddleware),
 		server.WithMetaHandler(transmeta.ServerHTTP2Handler),
 		server.WithMetaHandler(transmeta.ServerTTHeaderHandler),
 	}
+	if cfg.enableConcurrencyMetrics {
+		sOpts = append(sOpts, server.WithLimitReporter(kitexTracer))
+	}
 
 	return &serverSuite{sOpts}
 }
@@ -90,6 +98,7 @@ func NewGRPCClientSuite(opts ...Option) *clientSuite {
 		client.WithMetaHandler(transmeta.ClientHTTP2Handler),
 		client.WithMetaHandler(transmeta.ClientTTHeaderHandler),
 	}
+	cOpts = append(cOpts, clientStreamOptions()...)
 	return &clientSuite{cOpts}
 }
 
@@ -102,5 +111,27 @@ func NewFramedClientSuite(opts ...Option) *clientSuite {
 		client.WithTransportProtocol(transport.Framed),
 		client.WithMetaHandler(transmeta.ClientTTHeaderHandler),
 	}
+	cOpts = append(cOpts, clientStreamOptions()...)
 	return &clientSuite{cOpts}
 }
+
+// NewBufferedClientSuite is the client suite of the Buffered Thrift transport, the transport has no
+// headers so the trace context is only propagated when a request carrier is configured
+func NewBufferedClientSuite(opts ...Option) *clientSuite {
+	clientOpts, cfg := NewClientOption(opts...)
+	cOpts := []client.Option{
+		clientOpts,
+		client.WithMiddleware(ClientMiddleware(cfg)),
+		client.WithTransportProtocol(transport.PurePayload),
+	}
+	cOpts = append(cOpts, clientStreamOptions()...)
+	return &clientSuite{cOpts}
+}
+
+// clientStreamOptions records the size of the stream messages of the clients
+func clientStreamOptions() []client.Option {
+	return streamclient.GetClientOptions([]streamclient.Option{
+		streamclient.WithRecvMiddleware(StreamRecvMiddleware),
+		streamclient.WithSendMiddleware(StreamSendMiddleware),
+	})
+}
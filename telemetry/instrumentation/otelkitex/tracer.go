@@ -17,22 +17,28 @@
 // Package prometheus provides the extend implement of prometheus.
 package otelkitex
 
+import (
+	"go.opentelemetry.io/otel/trace"
+)
+
 // NewServerTracer provides tracer for server access, addr and path is the scrape_configs for prometheus server.
 func NewServerTracer(options ...Option) *KitexTracer {
 	cfg := NewConfig(options)
 
 	return &KitexTracer{
-		measure: cfg.measure,
-		cfg:     cfg,
+		measure:  cfg.measure,
+		cfg:      cfg,
+		spanKind: trace.SpanKindServer,
 	}
 }
 
-// NewClientTracer provides tracer for server access, addr and path is the scrape_configs for prometheus server.
+// NewClientTracer provides tracer for client access, it starts a client span for every outgoing call.
 func NewClientTracer(options ...Option) *KitexTracer {
 	cfg := NewConfig(options)
 
 	return &KitexTracer{
-		measure: cfg.measure,
-		cfg:     cfg,
+		measure:  cfg.measure,
+		cfg:      cfg,
+		spanKind: trace.SpanKindClient,
 	}
 }
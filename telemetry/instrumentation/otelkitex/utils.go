@@ -15,10 +15,12 @@
 package otelkitex
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/internal"
 	"github.com/cloudwego/kitex/pkg/rpcinfo"
 	"github.com/cloudwego/kitex/pkg/stats"
 	"go.opentelemetry.io/otel/attribute"
@@ -31,9 +33,22 @@ import (
 // naming rule: $package.$service/$method
 func spanNaming(ri rpcinfo.RPCInfo) string {
 	if ri.Invocation().PackageName() != "" {
-		return ri.Invocation().PackageName() + "." + ri.Invocation().ServiceName() + "/" + ri.Invocation().MethodName()
+		return ri.Invocation().PackageName() + "." + rpcServiceName(ri) + "/" + rpcMethodName(ri)
 	}
-	return ri.Invocation().ServiceName() + "/" + ri.Invocation().MethodName()
+	return rpcServiceName(ri) + "/" + rpcMethodName(ri)
+}
+
+// setCustomSpanAttributes adds the attributes of the span attributes func to the span of the call
+func setCustomSpanAttributes(ctx context.Context, cfg *Config, req, resp interface{}) {
+	if cfg.spanAttributesFunc == nil {
+		return
+	}
+	tc := internal.TraceCarrierFromContext(ctx)
+	if tc == nil || tc.Span() == nil || !tc.Span().IsRecording() {
+		return
+	}
+
+	tc.Span().SetAttributes(cfg.spanAttributesFunc(rpcinfo.GetRPCInfo(ctx), requestOf(req), responseOf(resp))...)
 }
 
 // recordErrorSpanWithStack record error with stack
@@ -37,15 +37,29 @@ func NewMeasure(opts ...Option) Measure {
 	}
 }
 
+// Inc Counter interface implementation, metric types not registered are ignored
 func (m *MeasureImpl) Inc(ctx context.Context, metricType string, labels ...label.CwLabel) error {
-	return m.counters[metricType].Inc(ctx, labels...)
+	counter, ok := m.counters[metricType]
+	if !ok {
+		return nil
+	}
+	return counter.Inc(ctx, labels...)
 }
 
+// Add Counter interface implementation, metric types not registered are ignored
 func (m *MeasureImpl) Add(ctx context.Context, metricType string, value int, labels ...label.CwLabel) error {
-	return m.counters[metricType].Add(ctx, value, labels...)
+	counter, ok := m.counters[metricType]
+	if !ok {
+		return nil
+	}
+	return counter.Add(ctx, value, labels...)
 }
 
-// Record Recorder interface implementation
+// Record Recorder interface implementation, metric types not registered are ignored
 func (m *MeasureImpl) Record(ctx context.Context, metricType string, value float64, labels ...label.CwLabel) error {
-	return m.recoders[metricType].Record(ctx, value, labels...)
+	recorder, ok := m.recoders[metricType]
+	if !ok {
+		return nil
+	}
+	return recorder.Record(ctx, value, labels...)
 }
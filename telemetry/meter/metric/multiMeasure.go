@@ -0,0 +1,66 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metric
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+)
+
+var _ Measure = &MultiMeasure{}
+
+// MultiMeasure fans every measurement out to several measures, e.g. an otel and a prometheus one
+type MultiMeasure struct {
+	measures []Measure
+}
+
+// NewMultiMeasure combines measures into one, nil measures are skipped
+func NewMultiMeasure(measures ...Measure) Measure {
+	m := &MultiMeasure{}
+	for _, measure := range measures {
+		if measure != nil {
+			m.measures = append(m.measures, measure)
+		}
+	}
+	return m
+}
+
+func (m *MultiMeasure) Inc(ctx context.Context, metricType string, labels ...label.CwLabel) error {
+	var errs []error
+	for _, measure := range m.measures {
+		errs = append(errs, measure.Inc(ctx, metricType, labels...))
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiMeasure) Add(ctx context.Context, metricType string, value int, labels ...label.CwLabel) error {
+	var errs []error
+	for _, measure := range m.measures {
+		errs = append(errs, measure.Add(ctx, metricType, value, labels...))
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiMeasure) Record(ctx context.Context, metricType string, value float64, labels ...label.CwLabel) error {
+	var errs []error
+	for _, measure := range m.measures {
+		errs = append(errs, measure.Record(ctx, metricType, value, labels...))
+	}
+	return errors.Join(errs...)
+}
@@ -50,6 +50,34 @@ func (o OtelCounter) Add(ctx context.Context, value int, labels ...label.CwLabel
 	return nil
 }
 
+var _ Counter = &OtelUpDownCounter{}
+
+// OtelUpDownCounter is a counter which can go down when a negative value is added
+type OtelUpDownCounter struct {
+	counter metric.Int64UpDownCounter
+}
+
+func NewOtelUpDownCounter(counter metric.Int64UpDownCounter) Counter {
+	if counter == nil {
+		return nil
+	}
+	return OtelUpDownCounter{
+		counter: counter,
+	}
+}
+
+func (o OtelUpDownCounter) Inc(ctx context.Context, labels ...label.CwLabel) error {
+	otelLabel := label.ToOtelsFromCwLabel(labels)
+	o.counter.Add(ctx, 1, metric.WithAttributes(otelLabel...))
+	return nil
+}
+
+func (o OtelUpDownCounter) Add(ctx context.Context, value int, labels ...label.CwLabel) error {
+	otelLabel := label.ToOtelsFromCwLabel(labels)
+	o.counter.Add(ctx, int64(value), metric.WithAttributes(otelLabel...))
+	return nil
+}
+
 var _ Recorder = &OtelRecorder{}
 
 type OtelRecorder struct {
@@ -70,3 +98,25 @@ func (o OtelRecorder) Record(ctx context.Context, value float64, labels ...label
 	o.histogram.Record(ctx, value, metric.WithAttributes(otelLabel...))
 	return nil
 }
+
+var _ Recorder = &OtelGaugeRecorder{}
+
+// OtelGaugeRecorder is a recorder which keeps the last recorded value, like the current size of a pool
+type OtelGaugeRecorder struct {
+	gauge metric.Float64Gauge
+}
+
+func NewOtelGaugeRecorder(gauge metric.Float64Gauge) Recorder {
+	if gauge == nil {
+		return nil
+	}
+	return &OtelGaugeRecorder{
+		gauge: gauge,
+	}
+}
+
+func (o OtelGaugeRecorder) Record(ctx context.Context, value float64, labels ...label.CwLabel) error {
+	otelLabel := label.ToOtelsFromCwLabel(labels)
+	o.gauge.Record(ctx, value, metric.WithAttributes(otelLabel...))
+	return nil
+}
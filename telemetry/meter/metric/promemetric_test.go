@@ -29,6 +29,7 @@ import (
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
 	prom "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -93,3 +94,15 @@ func TestMetrics(t *testing.T) {
 	assert.True(t, strings.Contains(bodyStr, `test_histogram_bucket{service="prometheus-test",test1="abc",test2="def",le="50000"} 0`))
 	assert.True(t, strings.Contains(bodyStr, `test_histogram_bucket{service="prometheus-test",test1="abc",test2="def",le="100000"} 1`))
 }
+
+func TestPromGaugeRecorder(t *testing.T) {
+	gauge := prom.NewGaugeVec(prom.GaugeOpts{Name: "test_gauge"}, []string{"test1"})
+	recorder := NewPromGaugeRecorder(gauge)
+	cwlabels := []label.CwLabel{{Key: "test1", Value: "abc"}}
+
+	// the last value is kept, recording it again does not add it up
+	assert.Nil(t, recorder.Record(context.Background(), 2, cwlabels...))
+	assert.Nil(t, recorder.Record(context.Background(), 2, cwlabels...))
+	assert.Nil(t, recorder.Record(context.Background(), 1, cwlabels...))
+	assert.Equal(t, float64(1), testutil.ToFloat64(gauge.WithLabelValues("abc")))
+}
@@ -55,6 +55,39 @@ func (p PromCounter) Add(ctx context.Context, value int, labels ...label.CwLabel
 	return nil
 }
 
+var _ Counter = &PromGauge{}
+
+// PromGauge is a counter which can go down when a negative value is added
+type PromGauge struct {
+	gauge *prometheus.GaugeVec
+}
+
+func NewPromGauge(gauge *prometheus.GaugeVec) *PromGauge {
+	return &PromGauge{
+		gauge: gauge,
+	}
+}
+
+func (p PromGauge) Inc(ctx context.Context, labels ...label.CwLabel) error {
+	pLabel := label.ToPromelabelFromCwLabel(labels)
+	gauge, err := p.gauge.GetMetricWith(pLabel)
+	if err != nil {
+		return err
+	}
+	gauge.Inc()
+	return nil
+}
+
+func (p PromGauge) Add(ctx context.Context, value int, labels ...label.CwLabel) error {
+	pLabel := label.ToPromelabelFromCwLabel(labels)
+	gauge, err := p.gauge.GetMetricWith(pLabel)
+	if err != nil {
+		return err
+	}
+	gauge.Add(float64(value))
+	return nil
+}
+
 var _ Recorder = &PromRecorder{}
 
 type PromRecorder struct {
@@ -76,3 +109,26 @@ func (p PromRecorder) Record(ctx context.Context, value float64, labels ...label
 	histogram.Observe(value)
 	return nil
 }
+
+var _ Recorder = &PromGaugeRecorder{}
+
+// PromGaugeRecorder is a recorder which keeps the last recorded value, like the current size of a pool
+type PromGaugeRecorder struct {
+	gauge *prometheus.GaugeVec
+}
+
+func NewPromGaugeRecorder(gauge *prometheus.GaugeVec) *PromGaugeRecorder {
+	return &PromGaugeRecorder{
+		gauge: gauge,
+	}
+}
+
+func (p PromGaugeRecorder) Record(ctx context.Context, value float64, labels ...label.CwLabel) error {
+	pLabel := label.ToPromelabelFromCwLabel(labels)
+	gauge, err := p.gauge.GetMetricWith(pLabel)
+	if err != nil {
+		return err
+	}
+	gauge.Set(value)
+	return nil
+}
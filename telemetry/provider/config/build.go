@@ -0,0 +1,272 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/kitex/pkg/klog"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/ot"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/otelhertz"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/instrumentation/otelkitex"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider/otelprovider"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider/promprovider"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider/telemetryProvider"
+)
+
+// LevelSetter is implemented by the logger adapters of cwgo-pkg (zap, slog, logrus and zerolog)
+type LevelSetter interface {
+	SetLevel(level hlog.Level)
+}
+
+// NewProvider builds every provider enabled in the configuration, serves the prometheus
+// scrape endpoint when configured and applies the log level to hlog, klog and the loggers.
+func NewProvider(c *Config, loggers ...LevelSetter) provider.Provider {
+	var opts []telemetryProvider.Option
+	if c.Otel != nil && enabled(c.Otel.Enabled) {
+		opts = append(opts, telemetryProvider.WithOtel(c.OtelOptions()...))
+	}
+	if c.Prom != nil && enabled(c.Prom.Enabled) {
+		opts = append(opts, telemetryProvider.WithProm(c.PromOptions()...))
+	}
+
+	p := telemetryProvider.NewTelemetryProvider(opts...)
+
+	if c.Prom != nil && enabled(c.Prom.Enabled) && c.Prom.Addr != "" {
+		promprovider.Server(c.Prom.Addr, c.Prom.Path, p)
+	}
+
+	c.ApplyLogLevel(loggers...)
+
+	return p
+}
+
+// Sampler returns the configured sampler, sdktrace.AlwaysSample when not configured
+func (c *Config) Sampler() sdktrace.Sampler {
+	if c.Otel == nil || c.Otel.Tracing == nil || c.Otel.Tracing.Sampler == nil {
+		return sdktrace.AlwaysSample()
+	}
+	return c.Otel.Tracing.Sampler.build()
+}
+
+func (c *SamplerConfig) build() sdktrace.Sampler {
+	switch c.Type {
+	case SamplerAlwaysOff:
+		return sdktrace.NeverSample()
+	case SamplerTraceIDRatio:
+		return sdktrace.TraceIDRatioBased(c.Ratio)
+	case SamplerParentBasedAlwaysOn:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	case SamplerParentBasedAlwaysOff:
+		return sdktrace.ParentBased(sdktrace.NeverSample())
+	case SamplerParentBasedTraceIDRatio:
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.Ratio))
+	default:
+		return sdktrace.AlwaysSample()
+	}
+}
+
+// Propagator returns the configured propagators, nil when not configured
+func (c *Config) Propagator() propagation.TextMapPropagator {
+	if c.Otel == nil || len(c.Otel.Propagators) == 0 {
+		return nil
+	}
+
+	propagators := make([]propagation.TextMapPropagator, 0, len(c.Otel.Propagators))
+	for _, name := range c.Otel.Propagators {
+		switch name {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case PropagatorB3Multi:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case PropagatorOT:
+			propagators = append(propagators, ot.OT{})
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...)
+}
+
+// OtelOptions returns the otelprovider options of the configuration
+func (c *Config) OtelOptions() []otelprovider.Option {
+	if c.Otel == nil {
+		return nil
+	}
+
+	opts := c.serviceOtelOptions()
+	if c.Otel.Endpoint != "" {
+		opts = append(opts, otelprovider.WithExportEndpoint(c.Otel.Endpoint))
+	}
+	if c.Otel.Insecure {
+		opts = append(opts, otelprovider.WithInsecure())
+	}
+	if c.Otel.Compression {
+		opts = append(opts, otelprovider.WithEnableCompression())
+	}
+	if len(c.Otel.Headers) > 0 {
+		opts = append(opts, otelprovider.WithHeaders(c.Otel.Headers))
+	}
+	if p := c.Propagator(); p != nil {
+		opts = append(opts, otelprovider.WithTextMapPropagator(p))
+	}
+	for _, protocol := range c.Otel.Protocols {
+		switch protocol {
+		case ProtocolRPC:
+			opts = append(opts, otelprovider.WithRPCServer())
+		case ProtocolHTTP:
+			opts = append(opts, otelprovider.WithHttpServer())
+		}
+	}
+	switch c.Otel.Role {
+	case RoleServer:
+		opts = append(opts, otelprovider.WithServer())
+	case RoleClient:
+		opts = append(opts, otelprovider.WithClient())
+	}
+
+	if c.Otel.Tracing != nil {
+		opts = append(opts, otelprovider.WithEnableTracing(enabled(c.Otel.Tracing.Enabled)))
+	}
+	opts = append(opts, otelprovider.WithSampler(c.Sampler()))
+
+	if m := c.Otel.Metrics; m != nil {
+		opts = append(opts,
+			otelprovider.WithEnableMetrics(enabled(m.Enabled)),
+			otelprovider.WithMetricsExportInterval(time.Duration(m.ExportInterval)),
+			otelprovider.WithEnableRuntimeMetrics(enabled(m.Runtime)),
+			otelprovider.WithRuntimeMetricsReadInterval(time.Duration(m.RuntimeReadInterval)),
+			otelprovider.WithEnableHostMetrics(m.Host),
+			otelprovider.WithEnableProcessMetrics(m.Process),
+		)
+	}
+
+	return opts
+}
+
+func (c *Config) serviceOtelOptions() []otelprovider.Option {
+	var opts []otelprovider.Option
+	if c.Service.Name != "" {
+		opts = append(opts, otelprovider.WithServiceName(c.Service.Name))
+	}
+	if c.Service.Namespace != "" {
+		opts = append(opts, otelprovider.WithServiceNamespace(c.Service.Namespace))
+	}
+	if c.Service.Environment != "" {
+		opts = append(opts, otelprovider.WithDeploymentEnvironment(c.Service.Environment))
+	}
+	return opts
+}
+
+// PromOptions returns the promprovider options of the configuration
+func (c *Config) PromOptions() []promprovider.Option {
+	if c.Prom == nil {
+		return nil
+	}
+
+	var opts []promprovider.Option
+	if c.Prom.Name != "" {
+		opts = append(opts, promprovider.WithServiceName(c.Prom.Name))
+	}
+	if len(c.Prom.Buckets) > 0 {
+		opts = append(opts, promprovider.WithHistogramBuckets(c.Prom.Buckets))
+	}
+	for _, protocol := range c.Prom.Protocols {
+		switch protocol {
+		case ProtocolRPC:
+			opts = append(opts, promprovider.WithRPCServer())
+		case ProtocolHTTP:
+			opts = append(opts, promprovider.WithHttpServer())
+		}
+	}
+	return opts
+}
+
+// KitexOptions returns the otelkitex options of the configuration
+func (c *Config) KitexOptions() []otelkitex.Option {
+	if c.Kitex == nil {
+		return nil
+	}
+
+	var opts []otelkitex.Option
+	if c.Kitex.RecordSourceOperation {
+		opts = append(opts, otelkitex.WithRecordSourceOperation(true))
+	}
+	if c.Kitex.EnableGRPCMetadata {
+		opts = append(opts, otelkitex.WithEnableGRPCMetadata())
+	}
+	return opts
+}
+
+// HertzOptions returns the otelhertz options of the configuration
+func (c *Config) HertzOptions() []otelhertz.Option {
+	if c.Hertz == nil {
+		return nil
+	}
+
+	var opts []otelhertz.Option
+	if c.Hertz.RecordSourceOperation {
+		opts = append(opts, otelhertz.WithRecordSourceOperation(true))
+	}
+	if len(c.Hertz.IgnorePaths) > 0 {
+		ignorePaths := c.Hertz.IgnorePaths
+		opts = append(opts, otelhertz.WithShouldIgnore(func(ctx context.Context, rc *app.RequestContext) bool {
+			path := string(rc.Path())
+			for _, ignorePath := range ignorePaths {
+				if path == ignorePath {
+					return true
+				}
+			}
+			return false
+		}))
+	}
+	return opts
+}
+
+// LogLevel returns the configured log level, false when not configured
+func (c *Config) LogLevel() (hlog.Level, bool) {
+	if c.Log == nil {
+		return 0, false
+	}
+	level, ok := logLevels[strings.ToLower(c.Log.Level)]
+	return level, ok
+}
+
+// ApplyLogLevel sets the configured log level on hlog, klog and the loggers
+func (c *Config) ApplyLogLevel(loggers ...LevelSetter) {
+	level, ok := c.LogLevel()
+	if !ok {
+		return
+	}
+	hlog.SetLevel(level)
+	// klog and hlog levels are declared in the same order
+	klog.SetLevel(klog.Level(level))
+	for _, logger := range loggers {
+		logger.SetLevel(level)
+	}
+}
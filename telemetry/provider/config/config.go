@@ -0,0 +1,233 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config builds the telemetry stack (providers, samplers, exporters, log levels and
+// instrumentation options) from a declarative YAML or JSON configuration.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format of a configuration file
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// Config describes the whole telemetry stack of a service
+type Config struct {
+	Service ServiceConfig `json:"service" yaml:"service"`
+	Otel    *OtelConfig   `json:"otel,omitempty" yaml:"otel,omitempty"`
+	Prom    *PromConfig   `json:"prom,omitempty" yaml:"prom,omitempty"`
+	Log     *LogConfig    `json:"log,omitempty" yaml:"log,omitempty"`
+	Kitex   *KitexConfig  `json:"kitex,omitempty" yaml:"kitex,omitempty"`
+	Hertz   *HertzConfig  `json:"hertz,omitempty" yaml:"hertz,omitempty"`
+}
+
+// ServiceConfig resource attributes describing the service
+type ServiceConfig struct {
+	Name        string `json:"name" yaml:"name"`
+	Namespace   string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+}
+
+// OtelConfig configures the opentelemetry provider
+type OtelConfig struct {
+	// Enabled the provider is enabled when omitted
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// Endpoint of the otlp grpc exporter, the exporter default is used when empty
+	Endpoint    string            `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Insecure    bool              `json:"insecure,omitempty" yaml:"insecure,omitempty"`
+	Compression bool              `json:"compression,omitempty" yaml:"compression,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Propagators names as in OTEL_PROPAGATORS: tracecontext, baggage, b3, b3multi, ot
+	Propagators []string `json:"propagators,omitempty" yaml:"propagators,omitempty"`
+	// Protocols instrumented by the provider metrics: rpc, http
+	Protocols []string `json:"protocols,omitempty" yaml:"protocols,omitempty"`
+	// Role of the instance in the metric names: server, client or empty
+	Role string `json:"role,omitempty" yaml:"role,omitempty"`
+
+	Tracing *TracingConfig `json:"tracing,omitempty" yaml:"tracing,omitempty"`
+	Metrics *MetricsConfig `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+}
+
+// TracingConfig configures tracing of the opentelemetry provider
+type TracingConfig struct {
+	// Enabled tracing is enabled when omitted
+	Enabled *bool          `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Sampler *SamplerConfig `json:"sampler,omitempty" yaml:"sampler,omitempty"`
+}
+
+// SamplerConfig configures the trace sampler
+type SamplerConfig struct {
+	// Type as in OTEL_TRACES_SAMPLER: always_on, always_off, traceidratio,
+	// parentbased_always_on, parentbased_always_off, parentbased_traceidratio
+	Type string `json:"type" yaml:"type"`
+	// Ratio of sampled traces for the traceidratio samplers, in [0, 1]
+	Ratio float64 `json:"ratio,omitempty" yaml:"ratio,omitempty"`
+}
+
+// MetricsConfig configures metrics of the opentelemetry provider
+type MetricsConfig struct {
+	// Enabled metrics are enabled when omitted
+	Enabled        *bool    `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	ExportInterval Duration `json:"export_interval,omitempty" yaml:"export_interval,omitempty"`
+	// Runtime enables go runtime metrics, enabled when omitted
+	Runtime             *bool    `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	RuntimeReadInterval Duration `json:"runtime_read_interval,omitempty" yaml:"runtime_read_interval,omitempty"`
+	Host                bool     `json:"host,omitempty" yaml:"host,omitempty"`
+	Process             bool     `json:"process,omitempty" yaml:"process,omitempty"`
+}
+
+// PromConfig configures the prometheus provider
+type PromConfig struct {
+	// Enabled the provider is enabled when omitted
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// Name prefix of the metric names
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Protocols instrumented by the provider metrics: rpc, http
+	Protocols []string  `json:"protocols,omitempty" yaml:"protocols,omitempty"`
+	Buckets   []float64 `json:"buckets,omitempty" yaml:"buckets,omitempty"`
+	// Addr and Path of the scrape endpoint, not served when Addr is empty
+	Addr string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// LogConfig configures the log level of hlog, klog and the registered loggers
+type LogConfig struct {
+	// Level one of trace, debug, info, notice, warn, error, fatal
+	Level string `json:"level" yaml:"level"`
+}
+
+// KitexConfig configures the otelkitex instrumentation
+type KitexConfig struct {
+	RecordSourceOperation bool `json:"record_source_operation,omitempty" yaml:"record_source_operation,omitempty"`
+	EnableGRPCMetadata    bool `json:"enable_grpc_metadata,omitempty" yaml:"enable_grpc_metadata,omitempty"`
+}
+
+// HertzConfig configures the otelhertz instrumentation
+type HertzConfig struct {
+	RecordSourceOperation bool `json:"record_source_operation,omitempty" yaml:"record_source_operation,omitempty"`
+	// IgnorePaths requests paths which are neither traced nor measured
+	IgnorePaths []string `json:"ignore_paths,omitempty" yaml:"ignore_paths,omitempty"`
+}
+
+// Duration is a time.Duration decoded from a string such as "15s" or "1m30s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"15s\": %w", err)
+	}
+	return d.parse(s)
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"15s\": %w", err)
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Load reads, decodes and validates the configuration file, the format is chosen by the file extension
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var format Format
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		format = FormatYAML
+	case ".json":
+		format = FormatJSON
+	default:
+		return nil, fmt.Errorf("telemetry config %s: unsupported file extension %q, expect .yaml, .yml or .json", path, ext)
+	}
+
+	cfg, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes and validates a configuration, unknown fields are rejected
+func Parse(data []byte, format Format) (*Config, error) {
+	cfg := &Config{}
+
+	switch format {
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		// an empty document is an empty configuration
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("decode yaml: %w", err)
+		}
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("decode json: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// enabled reports whether an optional switch is on, switches are on when omitted
+func enabled(b *bool) bool {
+	return b == nil || *b
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/stretchr/testify/assert"
+)
+
+const testYAML = `
+service:
+  name: echo
+  namespace: test-ns
+  environment: test-env
+otel:
+  endpoint: localhost:4317
+  insecure: true
+  propagators: [tracecontext, baggage, b3]
+  protocols: [rpc]
+  role: server
+  tracing:
+    sampler:
+      type: parentbased_traceidratio
+      ratio: 0.25
+  metrics:
+    export_interval: 30s
+    runtime: false
+prom:
+  enabled: false
+log:
+  level: warn
+kitex:
+  record_source_operation: true
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  Format
+		wantErr []string
+	}{
+		{
+			name:   "valid yaml",
+			data:   testYAML,
+			format: FormatYAML,
+		},
+		{
+			name:   "valid json",
+			data:   `{"service":{"name":"echo"},"otel":{"tracing":{"sampler":{"type":"always_on"}}},"log":{"level":"DEBUG"}}`,
+			format: FormatJSON,
+		},
+		{
+			name:   "empty yaml",
+			data:   "",
+			format: FormatYAML,
+		},
+		{
+			name:    "unknown yaml field",
+			data:    "otel:\n  endpont: localhost:4317\n",
+			format:  FormatYAML,
+			wantErr: []string{"field endpont not found"},
+		},
+		{
+			name:    "unknown json field",
+			data:    `{"log":{"levl":"info"}}`,
+			format:  FormatJSON,
+			wantErr: []string{`unknown field "levl"`},
+		},
+		{
+			name:    "invalid duration",
+			data:    "otel:\n  metrics:\n    export_interval: 15\n",
+			format:  FormatYAML,
+			wantErr: []string{"decode yaml"},
+		},
+		{
+			name: "invalid values",
+			data: `
+otel:
+  propagators: [jaeger]
+  role: gateway
+  tracing:
+    sampler:
+      type: traceidratio
+      ratio: 1.5
+prom:
+  buckets: [10, 5]
+log:
+  level: verbose
+`,
+			format: FormatYAML,
+			wantErr: []string{
+				"service.name: must not be empty when otel is enabled",
+				`otel.propagators[0]: unknown value "jaeger"`,
+				`otel.role: unknown value "gateway"`,
+				"otel.tracing.sampler.ratio: must be in [0, 1], got 1.5",
+				"prom.protocols: must list at least one of",
+				"prom.buckets: must be sorted in increasing order",
+				`log.level: unknown value "verbose"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.data), tt.format)
+			if len(tt.wantErr) == 0 {
+				assert.Nil(t, err)
+				assert.NotNil(t, cfg)
+				return
+			}
+			assert.NotNil(t, err)
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "telemetry.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte(testYAML), 0o644))
+
+	cfg, err := Load(path)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "echo", cfg.Service.Name)
+	assert.Equal(t, Duration(30*time.Second), cfg.Otel.Metrics.ExportInterval)
+	assert.Equal(t, "ParentBased{root:TraceIDRatioBased{0.25},remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}", cfg.Sampler().Description())
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage", "b3"}, cfg.Propagator().Fields())
+	assert.NotEmpty(t, cfg.OtelOptions())
+	assert.Empty(t, cfg.HertzOptions())
+	assert.Len(t, cfg.KitexOptions(), 1)
+
+	level, ok := cfg.LogLevel()
+	assert.True(t, ok)
+	assert.Equal(t, hlog.LevelWarn, level)
+
+	_, err = Load(filepath.Join(dir, "telemetry.toml"))
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "telemetry.toml"), nil, 0o644))
+	_, err = Load(filepath.Join(dir, "telemetry.toml"))
+	assert.ErrorContains(t, err, "unsupported file extension")
+}
@@ -0,0 +1,369 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/global"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/metric"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider/otelprovider"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider/promprovider"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider/telemetryProvider"
+)
+
+// ErrRestartRequired is returned by Reloadable.Update when the new configuration changes fields
+// which can only be applied by building a new provider
+var ErrRestartRequired = errors.New("changes require a restart")
+
+// metricsIntervalSetter is implemented by the otelprovider provider
+type metricsIntervalSetter interface {
+	SetMetricsExportInterval(interval time.Duration)
+}
+
+// Reloadable is a telemetry stack whose sampler, log levels, metrics export interval and
+// tracing/metrics enable flags can be changed at runtime.
+//
+// The sampling decision is taken when a span starts, so in-flight spans keep the decision they
+// were started with. otelkitex and otelhertz tracers must be created after NewReloadable so that
+// they record through the switchable global measure.
+type Reloadable struct {
+	mu      sync.Mutex
+	current atomic.Pointer[Config]
+
+	provider    provider.Provider
+	sampler     *reloadableSampler
+	otelMetrics *switchMeasure
+	promMetrics *switchMeasure
+	loggers     []LevelSetter
+}
+
+// NewReloadable builds the telemetry stack of the configuration like NewProvider, the tracing and
+// metrics pipelines are always built so that they can be enabled later on.
+func NewReloadable(c *Config, loggers ...LevelSetter) *Reloadable {
+	r := &Reloadable{
+		sampler:     &reloadableSampler{},
+		otelMetrics: &switchMeasure{},
+		promMetrics: &switchMeasure{},
+		loggers:     loggers,
+	}
+	r.sampler.store(tracingSampler(c))
+
+	var providers []telemetryProvider.Option
+	if c.Otel != nil && enabled(c.Otel.Enabled) {
+		otelProvider := otelprovider.NewOpenTelemetryProvider(append(c.OtelOptions(),
+			otelprovider.WithEnableTracing(true),
+			otelprovider.WithEnableMetrics(true),
+			otelprovider.WithSampler(r.sampler),
+		)...)
+		r.otelMetrics.measure = measureOf(otelProvider)
+		providers = append(providers, telemetryProvider.WithProvider(otelProvider))
+	}
+	if c.Prom != nil {
+		promProvider := promprovider.NewPromProvider(c.PromOptions()...)
+		r.promMetrics.measure = measureOf(promProvider)
+		providers = append(providers, telemetryProvider.WithProvider(promProvider))
+	}
+	r.provider = telemetryProvider.NewTelemetryProvider(providers...)
+
+	// replace the measure combined by the telemetry provider by the switchable one
+	global.SetTracerMeasure(metric.NewMultiMeasure(r.otelMetrics, r.promMetrics))
+
+	if c.Prom != nil && c.Prom.Addr != "" {
+		promprovider.Server(c.Prom.Addr, c.Prom.Path, r.provider)
+	}
+
+	r.apply(c)
+	r.current.Store(c)
+
+	return r
+}
+
+// Provider returns the provider built from the configuration
+func (r *Reloadable) Provider() provider.Provider {
+	return r.provider
+}
+
+// Config returns the configuration currently applied
+func (r *Reloadable) Config() *Config {
+	return r.current.Load()
+}
+
+// Update validates and applies a new configuration. The reloadable fields are
+// otel.tracing.enabled, otel.tracing.sampler, otel.metrics.enabled, otel.metrics.export_interval,
+// prom.enabled and log.level; changes to other fields are not applied, are left out of Config
+// and are reported by an error wrapping ErrRestartRequired on every update requesting them.
+func (r *Reloadable) Update(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	restart := restartRequiredFields(r.current.Load(), c)
+	applied := c
+	if len(restart) > 0 {
+		applied = withReloadable(r.current.Load(), c)
+	}
+	r.apply(applied)
+	r.current.Store(applied)
+
+	if len(restart) > 0 {
+		return fmt.Errorf("%w: %s", ErrRestartRequired, strings.Join(restart, ", "))
+	}
+	return nil
+}
+
+// Watch polls the configuration file every interval and applies it when its content changes,
+// until ctx is done. Invalid configurations are logged and ignored.
+func (r *Reloadable) Watch(ctx context.Context, path string, interval time.Duration) {
+	last, _ := os.ReadFile(path)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				hlog.Warnf("telemetry config %s: %s", path, err.Error())
+				continue
+			}
+			if bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+
+			c, err := Load(path)
+			if err != nil {
+				hlog.Errorf("telemetry config reload ignored: %s", err.Error())
+				continue
+			}
+			if err = r.Update(c); err != nil {
+				hlog.Warnf("telemetry config %s reloaded partially: %s", path, err.Error())
+			}
+		}
+	}()
+}
+
+func (r *Reloadable) apply(c *Config) {
+	r.sampler.store(tracingSampler(c))
+
+	r.otelMetrics.enabled.Store(c.Otel != nil && enabled(c.Otel.Enabled) &&
+		(c.Otel.Metrics == nil || enabled(c.Otel.Metrics.Enabled)))
+	r.promMetrics.enabled.Store(c.Prom != nil && enabled(c.Prom.Enabled))
+
+	if c.Otel != nil && c.Otel.Metrics != nil && c.Otel.Metrics.ExportInterval > 0 {
+		forEachProvider(r.provider, func(p provider.Provider) {
+			if setter, ok := p.(metricsIntervalSetter); ok {
+				setter.SetMetricsExportInterval(time.Duration(c.Otel.Metrics.ExportInterval))
+			}
+		})
+	}
+
+	c.ApplyLogLevel(r.loggers...)
+}
+
+// tracingSampler returns the configured sampler, or a sampler dropping every span when tracing is disabled
+func tracingSampler(c *Config) sdktrace.Sampler {
+	if c.Otel == nil || !enabled(c.Otel.Enabled) || (c.Otel.Tracing != nil && !enabled(c.Otel.Tracing.Enabled)) {
+		return sdktrace.NeverSample()
+	}
+	return c.Sampler()
+}
+
+// restartRequiredFields lists the sections changed outside of the reloadable fields
+func restartRequiredFields(old, c *Config) []string {
+	o, n := withoutReloadable(old), withoutReloadable(c)
+
+	var fields []string
+	if !reflect.DeepEqual(o.Service, n.Service) {
+		fields = append(fields, "service")
+	}
+	if !reflect.DeepEqual(o.Otel, n.Otel) {
+		fields = append(fields, "otel")
+	}
+	if !reflect.DeepEqual(o.Prom, n.Prom) {
+		fields = append(fields, "prom")
+	}
+	if !reflect.DeepEqual(o.Kitex, n.Kitex) {
+		fields = append(fields, "kitex")
+	}
+	if !reflect.DeepEqual(o.Hertz, n.Hertz) {
+		fields = append(fields, "hertz")
+	}
+	return fields
+}
+
+// withoutReloadable returns a copy of the configuration with the reloadable fields cleared
+func withoutReloadable(c *Config) Config {
+	cp := *c
+	cp.Log = nil
+
+	if c.Otel != nil {
+		otel := *c.Otel
+		otel.Tracing, otel.Metrics = nil, nil
+		if c.Otel.Metrics != nil {
+			metrics := *c.Otel.Metrics
+			metrics.Enabled, metrics.ExportInterval = nil, 0
+			if !reflect.ValueOf(metrics).IsZero() {
+				otel.Metrics = &metrics
+			}
+		}
+		cp.Otel = &otel
+	}
+
+	if c.Prom != nil {
+		prom := *c.Prom
+		prom.Enabled = nil
+		cp.Prom = &prom
+	}
+
+	return cp
+}
+
+// withReloadable returns a copy of the applied configuration with the reloadable fields of c
+func withReloadable(applied, c *Config) *Config {
+	cp := *applied
+	cp.Log = c.Log
+
+	if applied.Otel != nil {
+		otel := *applied.Otel
+		otel.Tracing = nil
+		var metrics MetricsConfig
+		if applied.Otel.Metrics != nil {
+			metrics = *applied.Otel.Metrics
+		}
+		metrics.Enabled, metrics.ExportInterval = nil, 0
+		if c.Otel != nil {
+			otel.Tracing = c.Otel.Tracing
+			if c.Otel.Metrics != nil {
+				metrics.Enabled, metrics.ExportInterval = c.Otel.Metrics.Enabled, c.Otel.Metrics.ExportInterval
+			}
+		}
+		otel.Metrics = nil
+		if !reflect.ValueOf(metrics).IsZero() {
+			otel.Metrics = &metrics
+		}
+		cp.Otel = &otel
+	}
+
+	if applied.Prom != nil {
+		prom := *applied.Prom
+		prom.Enabled = nil
+		if c.Prom != nil {
+			prom.Enabled = c.Prom.Enabled
+		}
+		cp.Prom = &prom
+	}
+
+	return &cp
+}
+
+func forEachProvider(p provider.Provider, fn func(p provider.Provider)) {
+	if composite, ok := p.(provider.CompositeProvider); ok {
+		for _, sub := range composite.Providers() {
+			forEachProvider(sub, fn)
+		}
+		return
+	}
+	fn(p)
+}
+
+func measureOf(p provider.Provider) metric.Measure {
+	if mp, ok := p.(provider.MeasureProvider); ok {
+		return mp.Measure()
+	}
+	return nil
+}
+
+var _ sdktrace.Sampler = &reloadableSampler{}
+
+// reloadableSampler delegates to a sampler which can be swapped atomically
+type reloadableSampler struct {
+	sampler atomic.Value
+}
+
+type samplerHolder struct {
+	sdktrace.Sampler
+}
+
+func (s *reloadableSampler) store(sampler sdktrace.Sampler) {
+	s.sampler.Store(samplerHolder{sampler})
+}
+
+func (s *reloadableSampler) load() sdktrace.Sampler {
+	return s.sampler.Load().(samplerHolder).Sampler
+}
+
+func (s *reloadableSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return s.load().ShouldSample(p)
+}
+
+func (s *reloadableSampler) Description() string {
+	return "Reloadable{" + s.load().Description() + "}"
+}
+
+var _ metric.Measure = &switchMeasure{}
+
+// switchMeasure records to the underlying measure only while enabled
+type switchMeasure struct {
+	measure metric.Measure
+	enabled atomic.Bool
+}
+
+func (m *switchMeasure) Inc(ctx context.Context, metricType string, labels ...label.CwLabel) error {
+	if m.measure == nil || !m.enabled.Load() {
+		return nil
+	}
+	return m.measure.Inc(ctx, metricType, labels...)
+}
+
+func (m *switchMeasure) Add(ctx context.Context, metricType string, value int, labels ...label.CwLabel) error {
+	if m.measure == nil || !m.enabled.Load() {
+		return nil
+	}
+	return m.measure.Add(ctx, metricType, value, labels...)
+}
+
+func (m *switchMeasure) Record(ctx context.Context, metricType string, value float64, labels ...label.CwLabel) error {
+	if m.measure == nil || !m.enabled.Load() {
+		return nil
+	}
+	return m.measure.Record(ctx, metricType, value, labels...)
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/global"
+)
+
+type levelRecorder struct {
+	level hlog.Level
+}
+
+func (l *levelRecorder) SetLevel(level hlog.Level) {
+	l.level = level
+}
+
+// shutdown does not wait for the final export, no collector listens on the endpoint
+func shutdown(r *Reloadable) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_ = r.Provider().Shutdown(ctx)
+}
+
+const reloadYAML = `
+service:
+  name: echo
+otel:
+  endpoint: localhost:4317
+  insecure: true
+  tracing:
+    sampler:
+      type: traceidratio
+      ratio: 0.5
+  metrics:
+    export_interval: 30s
+    runtime: false
+log:
+  level: info
+`
+
+func TestReloadable(t *testing.T) {
+	cfg, err := Parse([]byte(reloadYAML), FormatYAML)
+	assert.Nil(t, err)
+
+	logger := &levelRecorder{}
+	r := NewReloadable(cfg, logger)
+	defer shutdown(r)
+
+	assert.Equal(t, "Reloadable{TraceIDRatioBased{0.5}}", r.sampler.Description())
+	assert.True(t, r.otelMetrics.enabled.Load())
+	assert.False(t, r.promMetrics.enabled.Load())
+	assert.Equal(t, hlog.LevelInfo, logger.level)
+	assert.NotNil(t, global.GetTracerMeasure())
+
+	next, err := Parse([]byte(`
+service:
+  name: echo
+otel:
+  endpoint: localhost:4317
+  insecure: true
+  tracing:
+    enabled: false
+  metrics:
+    enabled: false
+    export_interval: 5s
+    runtime: false
+log:
+  level: error
+`), FormatYAML)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Update(next))
+
+	assert.Equal(t, "Reloadable{AlwaysOffSampler}", r.sampler.Description())
+	assert.False(t, r.otelMetrics.enabled.Load())
+	assert.Equal(t, hlog.LevelError, logger.level)
+	assert.Equal(t, next, r.Config())
+
+	// service name is not reloadable, reloadable fields are still applied
+	restart, err := Parse([]byte(`
+service:
+  name: other
+otel:
+  endpoint: localhost:4317
+  insecure: true
+  metrics:
+    export_interval: 5s
+    runtime: false
+log:
+  level: warn
+`), FormatYAML)
+	assert.Nil(t, err)
+	err = r.Update(restart)
+	assert.ErrorIs(t, err, ErrRestartRequired)
+	assert.ErrorContains(t, err, "service")
+	assert.Equal(t, "Reloadable{AlwaysOnSampler}", r.sampler.Description())
+	assert.True(t, r.otelMetrics.enabled.Load())
+	assert.Equal(t, hlog.LevelWarn, logger.level)
+
+	// the fields which are not applied are kept out of the current configuration
+	applied := r.Config()
+	assert.Equal(t, "echo", applied.Service.Name)
+	assert.Equal(t, "warn", applied.Log.Level)
+	assert.Nil(t, applied.Otel.Tracing)
+	assert.Equal(t, Duration(5*time.Second), applied.Otel.Metrics.ExportInterval)
+
+	// and are reported again until the provider is rebuilt
+	err = r.Update(restart)
+	assert.ErrorIs(t, err, ErrRestartRequired)
+	assert.ErrorContains(t, err, "service")
+
+	// invalid configurations are rejected
+	assert.NotNil(t, r.Update(&Config{Log: &LogConfig{Level: "verbose"}}))
+	assert.Equal(t, applied, r.Config())
+}
+
+func TestReloadableWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte(reloadYAML), 0o644))
+
+	cfg, err := Load(path)
+	assert.Nil(t, err)
+
+	logger := &levelRecorder{}
+	r := NewReloadable(cfg, logger)
+	defer shutdown(r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Watch(ctx, path, 10*time.Millisecond)
+
+	assert.Nil(t, os.WriteFile(path, []byte(reloadYAML+"    \n"), 0o644))
+	assert.Nil(t, os.WriteFile(path, []byte(`
+service:
+  name: echo
+otel:
+  endpoint: localhost:4317
+  insecure: true
+  tracing:
+    sampler:
+      type: always_off
+  metrics:
+    export_interval: 30s
+    runtime: false
+log:
+  level: debug
+`), 0o644))
+
+	assert.Eventually(t, func() bool {
+		return r.Config().Log.Level == "debug"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "Reloadable{AlwaysOffSampler}", r.sampler.Description())
+}
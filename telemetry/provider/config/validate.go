@@ -0,0 +1,174 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorB3           = "b3"
+	PropagatorB3Multi      = "b3multi"
+	PropagatorOT           = "ot"
+
+	ProtocolRPC  = "rpc"
+	ProtocolHTTP = "http"
+
+	RoleServer = "server"
+	RoleClient = "client"
+)
+
+var (
+	samplerTypes = []string{
+		SamplerAlwaysOn, SamplerAlwaysOff, SamplerTraceIDRatio,
+		SamplerParentBasedAlwaysOn, SamplerParentBasedAlwaysOff, SamplerParentBasedTraceIDRatio,
+	}
+	propagatorNames = []string{PropagatorTraceContext, PropagatorBaggage, PropagatorB3, PropagatorB3Multi, PropagatorOT}
+	protocolNames   = []string{ProtocolRPC, ProtocolHTTP}
+	roleNames       = []string{"", RoleServer, RoleClient}
+
+	logLevels = map[string]hlog.Level{
+		"trace":  hlog.LevelTrace,
+		"debug":  hlog.LevelDebug,
+		"info":   hlog.LevelInfo,
+		"notice": hlog.LevelNotice,
+		"warn":   hlog.LevelWarn,
+		"error":  hlog.LevelError,
+		"fatal":  hlog.LevelFatal,
+	}
+)
+
+// fieldErrors collects validation errors prefixed by the path of the invalid field
+type fieldErrors []error
+
+func (e *fieldErrors) add(field, format string, args ...interface{}) {
+	*e = append(*e, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+}
+
+func (e *fieldErrors) oneOf(field, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.add(field, "unknown value %q, expect one of %s", value, strings.Join(quote(allowed), ", "))
+}
+
+// Validate checks the whole configuration and reports every invalid field
+func (c *Config) Validate() error {
+	var errs fieldErrors
+
+	if c.Otel != nil && enabled(c.Otel.Enabled) && c.Service.Name == "" {
+		errs.add("service.name", "must not be empty when otel is enabled")
+	}
+	if c.Otel != nil {
+		c.Otel.validate(&errs)
+	}
+	if c.Prom != nil {
+		c.Prom.validate(&errs)
+	}
+	if c.Log != nil {
+		c.Log.validate(&errs)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *OtelConfig) validate(errs *fieldErrors) {
+	for i, p := range c.Propagators {
+		errs.oneOf(fmt.Sprintf("otel.propagators[%d]", i), p, propagatorNames)
+	}
+	for i, p := range c.Protocols {
+		errs.oneOf(fmt.Sprintf("otel.protocols[%d]", i), p, protocolNames)
+	}
+	errs.oneOf("otel.role", c.Role, roleNames)
+
+	if c.Tracing != nil && c.Tracing.Sampler != nil {
+		c.Tracing.Sampler.validate(errs)
+	}
+	if c.Metrics != nil {
+		if c.Metrics.ExportInterval < 0 {
+			errs.add("otel.metrics.export_interval", "must not be negative, got %s", time.Duration(c.Metrics.ExportInterval))
+		}
+		if c.Metrics.RuntimeReadInterval < 0 {
+			errs.add("otel.metrics.runtime_read_interval", "must not be negative, got %s", time.Duration(c.Metrics.RuntimeReadInterval))
+		}
+	}
+}
+
+func (c *SamplerConfig) validate(errs *fieldErrors) {
+	errs.oneOf("otel.tracing.sampler.type", c.Type, samplerTypes)
+	switch c.Type {
+	case SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio:
+		if c.Ratio < 0 || c.Ratio > 1 {
+			errs.add("otel.tracing.sampler.ratio", "must be in [0, 1], got %v", c.Ratio)
+		}
+	default:
+		if c.Ratio != 0 {
+			errs.add("otel.tracing.sampler.ratio", "only allowed with %q and %q samplers", SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio)
+		}
+	}
+}
+
+func (c *PromConfig) validate(errs *fieldErrors) {
+	for i, p := range c.Protocols {
+		errs.oneOf(fmt.Sprintf("prom.protocols[%d]", i), p, protocolNames)
+	}
+	if enabled(c.Enabled) && len(c.Protocols) == 0 {
+		errs.add("prom.protocols", "must list at least one of %s", strings.Join(quote(protocolNames), ", "))
+	}
+	if !sort.Float64sAreSorted(c.Buckets) {
+		errs.add("prom.buckets", "must be sorted in increasing order")
+	}
+	if c.Addr != "" && !strings.HasPrefix(c.Path, "/") {
+		errs.add("prom.path", "must start with \"/\" when prom.addr is set, got %q", c.Path)
+	}
+}
+
+func (c *LogConfig) validate(errs *fieldErrors) {
+	if _, ok := logLevels[strings.ToLower(c.Level)]; !ok {
+		levels := make([]string, 0, len(logLevels))
+		for level := range logLevels {
+			levels = append(levels, level)
+		}
+		sort.Strings(levels)
+		errs.add("log.level", "unknown value %q, expect one of %s", c.Level, strings.Join(quote(levels), ", "))
+	}
+}
+
+func quote(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
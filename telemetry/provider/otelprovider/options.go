@@ -15,6 +15,8 @@
 package otelprovider
 
 import (
+	"time"
+
 	"go.opentelemetry.io/contrib/propagators/b3"
 	"go.opentelemetry.io/contrib/propagators/ot"
 	"go.opentelemetry.io/otel/attribute"
@@ -62,6 +64,13 @@ type config struct {
 	exportEnableCompression bool
 
 	instanceType string
+
+	enableRuntimeMetrics       bool
+	runtimeMetricsReadInterval time.Duration
+	enableHostMetrics          bool
+	enableProcessMetrics       bool
+
+	metricsExportInterval time.Duration
 }
 
 func newConfig(opts []Option) *config {
@@ -85,8 +94,10 @@ func defaultConfig() *config {
 			propagation.Baggage{},
 			propagation.TraceContext{},
 		),
-		enableHTTP: false,
-		enableRPC:  false,
+		enableHTTP:            false,
+		enableRPC:             false,
+		enableRuntimeMetrics:  true,
+		metricsExportInterval: 15 * time.Second,
 	}
 }
 
@@ -188,7 +199,8 @@ func WithSampler(sampler sdktrace.Sampler) Option {
 	})
 }
 
-// WithSdkTracerProvider configures sdkTracerProvider
+// WithSdkTracerProvider configures sdkTracerProvider, the provider does not track the span exports
+// and the queue of the given tracer provider so they are missing from the status
 func WithSdkTracerProvider(sdkTracerProvider *sdktrace.TracerProvider) Option {
 	return option(func(cfg *config) {
 		cfg.sdkTracerProvider = sdkTracerProvider
@@ -232,3 +244,41 @@ func WithEnableCompression() Option {
 		cfg.exportEnableCompression = true
 	})
 }
+
+// WithEnableRuntimeMetrics enable go runtime metrics, enabled by default.
+// The runtime, host and process metrics are exported by a single provider of the process at a time.
+func WithEnableRuntimeMetrics(enableRuntimeMetrics bool) Option {
+	return option(func(cfg *config) {
+		cfg.enableRuntimeMetrics = enableRuntimeMetrics
+	})
+}
+
+// WithRuntimeMetricsReadInterval configures the minimum interval between calls to runtime.ReadMemStats
+func WithRuntimeMetricsReadInterval(interval time.Duration) Option {
+	return option(func(cfg *config) {
+		cfg.runtimeMetricsReadInterval = interval
+	})
+}
+
+// WithEnableHostMetrics enable host metrics (cpu, memory, network) and process cpu time
+func WithEnableHostMetrics(enableHostMetrics bool) Option {
+	return option(func(cfg *config) {
+		cfg.enableHostMetrics = enableHostMetrics
+	})
+}
+
+// WithEnableProcessMetrics enable process metrics (cpu time, rss, open file descriptors)
+func WithEnableProcessMetrics(enableProcessMetrics bool) Option {
+	return option(func(cfg *config) {
+		cfg.enableProcessMetrics = enableProcessMetrics
+	})
+}
+
+// WithMetricsExportInterval configures the interval between two metrics exports
+func WithMetricsExportInterval(interval time.Duration) Option {
+	return option(func(cfg *config) {
+		if interval > 0 {
+			cfg.metricsExportInterval = interval
+		}
+	})
+}
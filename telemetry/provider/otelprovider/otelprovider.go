@@ -16,6 +16,8 @@ package otelprovider
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"time"
 
 	"github.com/cloudwego/kitex/pkg/klog"
@@ -27,7 +29,6 @@ import (
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
 
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider"
-	runtimemetrics "go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
@@ -43,11 +44,29 @@ const (
 	instrumentationNameHertz = "github.com/cloudwego-contrib/telemetry-opentelemetry/otelhertz"
 )
 
-var _ provider.Provider = &otelProvider{}
+var (
+	_ provider.Provider        = &otelProvider{}
+	_ provider.MeasureProvider = &otelProvider{}
+	_ provider.Flusher         = &otelProvider{}
+	_ provider.StatusReporter  = &otelProvider{}
+)
 
 type otelProvider struct {
-	traceExp      *otlptrace.Exporter
-	metricsPusher *metric.MeterProvider
+	traceExp       *otlptrace.Exporter
+	tracerProvider *sdktrace.TracerProvider
+	metricsPusher  *metric.MeterProvider
+	metricReader   *intervalReader
+	measure        cwmetric.Measure
+
+	// the statuses are nil when the pipeline is not built by the provider
+	traceStatus  *provider.ExportStatus
+	metricStatus *provider.ExportStatus
+	queuedSpans  *atomic.Int64
+}
+
+// Measure returns the measure built from the meter provider, nil if metrics are disabled
+func (p *otelProvider) Measure() cwmetric.Measure {
+	return p.measure
 }
 
 func (p *otelProvider) Shutdown(ctx context.Context) error {
@@ -63,17 +82,77 @@ func (p *otelProvider) Shutdown(ctx context.Context) error {
 		if err = p.metricsPusher.Shutdown(ctx); err != nil {
 			otel.Handle(err)
 		}
+		stopCollectors(p.metricsPusher)
 	}
 
 	return err
 }
 
+// ForceFlush exports all pending spans and metrics without shutting down the provider
+func (p *otelProvider) ForceFlush(ctx context.Context) error {
+	var errs []error
+
+	if p.tracerProvider != nil {
+		errs = append(errs, p.tracerProvider.ForceFlush(ctx))
+	}
+
+	if p.metricsPusher != nil {
+		errs = append(errs, p.metricsPusher.ForceFlush(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Status reports the health of the otlp exports, the queue depth is the number of spans not exported yet.
+// The trace and metric exports are tracked apart: the errors of the pipelines whose last export failed
+// are joined and the export time is the one of the pipeline exported least recently.
+// The exports and the queue are only tracked for the pipelines built by the provider, not for
+// the providers given with WithSdkTracerProvider or WithMeterProvider.
+func (p *otelProvider) Status() provider.Status {
+	queueDepth := p.queuedSpans.Load()
+	if queueDepth < 0 {
+		queueDepth = 0
+	}
+	status := provider.Status{QueueDepth: int(queueDepth)}
+
+	var errs []error
+	tracked := false
+	for _, s := range []*provider.ExportStatus{p.traceStatus, p.metricStatus} {
+		if s == nil {
+			continue
+		}
+		st := s.Status(0)
+		errs = append(errs, st.LastExportError)
+		if !tracked || st.LastExportTime.Before(status.LastExportTime) {
+			status.LastExportTime = st.LastExportTime
+		}
+		tracked = true
+	}
+	status.LastExportError = errors.Join(errs...)
+	return status
+}
+
+// SetMetricsExportInterval changes the metrics export interval at runtime,
+// it has no effect when the meter provider is configured by WithMeterProvider
+func (p *otelProvider) SetMetricsExportInterval(interval time.Duration) {
+	if p.metricReader != nil {
+		p.metricReader.SetInterval(interval)
+	}
+}
+
 // NewOpenTelemetryProvider Initializes an otlp trace and meter provider
 func NewOpenTelemetryProvider(opts ...Option) provider.Provider {
 	var (
-		err           error
-		traceExp      *otlptrace.Exporter
-		meterProvider *metric.MeterProvider
+		err            error
+		traceExp       *otlptrace.Exporter
+		tracerProvider *sdktrace.TracerProvider
+		meterProvider  *metric.MeterProvider
+		metricReader   *intervalReader
+		measure        cwmetric.Measure
+
+		traceStatus  *provider.ExportStatus
+		metricStatus *provider.ExportStatus
+		queuedSpans  = &atomic.Int64{}
 	)
 
 	ctx := context.TODO()
@@ -116,16 +195,23 @@ func NewOpenTelemetryProvider(opts ...Option) provider.Provider {
 			return nil
 		}
 
-		// trace processor
-		bsp := sdktrace.NewBatchSpanProcessor(traceExp)
-
 		// trace provider
-		tracerProvider := cfg.sdkTracerProvider
+		tracerProvider = cfg.sdkTracerProvider
 		if tracerProvider == nil {
+			// trace processor
+			traceStatus = &provider.ExportStatus{}
+			bsp := sdktrace.NewBatchSpanProcessor(&statusSpanExporter{
+				SpanExporter: traceExp,
+				status:       traceStatus,
+				queued:       queuedSpans,
+			})
 			tracerProvider = sdktrace.NewTracerProvider(
 				sdktrace.WithSampler(cfg.sampler),
 				sdktrace.WithResource(res),
-				sdktrace.WithSpanProcessor(bsp),
+				sdktrace.WithSpanProcessor(&queueCountingSpanProcessor{
+					SpanProcessor: bsp,
+					queued:        queuedSpans,
+				}),
 			)
 		}
 
@@ -159,16 +245,18 @@ func NewOpenTelemetryProvider(opts ...Option) provider.Provider {
 			if cfg.enableRPC {
 				handleInitErrk(err, "Failed to create the metric exporter")
 			}
-			// reader := metric.NewPeriodicReader(exporter)
-			reader := metric.WithReader(metric.NewPeriodicReader(metricExp, metric.WithInterval(15*time.Second)))
+			metricStatus = &provider.ExportStatus{}
+			metricReader = newIntervalReader(&statusMetricExporter{
+				Exporter: metricExp,
+				status:   metricStatus,
+			}, cfg.metricsExportInterval)
 
-			meterProvider = metric.NewMeterProvider(reader, metric.WithResource(res))
+			meterProvider = metric.NewMeterProvider(metric.WithReader(metricReader), metric.WithResource(res))
 		}
 
 		// meter pusher
 		otel.SetMeterProvider(meterProvider)
 
-		var measure cwmetric.Measure
 		var metrics []cwmetric.Option
 		if cfg.enableRPC {
 			meter := meterProvider.Meter(
@@ -185,10 +273,107 @@ func NewOpenTelemetryProvider(opts ...Option) provider.Provider {
 			HandleErr(err)
 			serverRetryMeasure, err := meter.Float64Histogram(semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerRetry))
 			HandleErr(err)
+			requestsPerRPCMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerRequestsPerRPC),
+				otelmetric.WithUnit("{count}"),
+				otelmetric.WithDescription("measures the number of messages received per streaming RPC"),
+			)
+			HandleErr(err)
+			responsesPerRPCMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerResponsesPerRPC),
+				otelmetric.WithUnit("{count}"),
+				otelmetric.WithDescription("measures the number of messages sent per streaming RPC"),
+			)
+			HandleErr(err)
+			streamDurationMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerStreamDuration),
+				otelmetric.WithUnit("ms"),
+				otelmetric.WithDescription("measures the duration of streaming RPC"),
+			)
+			HandleErr(err)
+			requestSizeMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerRequestSize),
+				otelmetric.WithUnit("By"),
+				otelmetric.WithDescription("measures size of RPC request messages"),
+			)
+			HandleErr(err)
+			responseSizeMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerResponseSize),
+				otelmetric.WithUnit("By"),
+				otelmetric.WithDescription("measures size of RPC response messages"),
+			)
+			HandleErr(err)
+			retryAttemptMeasure, err := meter.Int64Counter(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.RetryAttempt),
+				otelmetric.WithUnit("count"),
+				otelmetric.WithDescription("measures the attempts of the retried calls by outcome"),
+			)
+			HandleErr(err)
+			inFlightMeasure, err := meter.Int64UpDownCounter(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerInFlight),
+				otelmetric.WithUnit("{request}"),
+				otelmetric.WithDescription("measures the number of requests handled by the server"),
+			)
+			HandleErr(err)
+			queueWaitMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerQueueWait),
+				otelmetric.WithUnit("ms"),
+				otelmetric.WithDescription("measures the duration from the decoding of the request to the start of the handler"),
+			)
+			HandleErr(err)
+			limitRejectionMeasure, err := meter.Int64Counter(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerLimitRejection),
+				otelmetric.WithUnit("count"),
+				otelmetric.WithDescription("measures the requests rejected by the server limiter"),
+			)
+			HandleErr(err)
+			resolveDurationMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerResolveDuration),
+				otelmetric.WithUnit("ms"),
+				otelmetric.WithDescription("measures the duration of the service discovery lookups"),
+			)
+			HandleErr(err)
+			instancesMeasure, err := meter.Float64Gauge(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerInstances),
+				otelmetric.WithUnit("{instance}"),
+				otelmetric.WithDescription("measures the number of instances resolved for a target"),
+			)
+			HandleErr(err)
+			instancePickMeasure, err := meter.Int64Counter(
+				semantic.BuildMetricName("rpc", cfg.instanceType, semantic.ServerInstancePick),
+				otelmetric.WithUnit("count"),
+				otelmetric.WithDescription("measures the instances picked by the load balancer"),
+			)
+			HandleErr(err)
+			transportRecorder := func(name, description string) cwmetric.Recorder {
+				histogram, err := meter.Float64Histogram(
+					semantic.BuildMetricName("rpc", cfg.instanceType, name),
+					otelmetric.WithUnit("ms"),
+					otelmetric.WithDescription(description),
+				)
+				HandleErr(err)
+				return cwmetric.NewOtelRecorder(histogram)
+			}
 			metrics = append(metrics,
 				cwmetric.WithCounter(semantic.RPCCounter, cwmetric.NewOtelCounter(serverRequestCountMeasure)),
 				cwmetric.WithRecorder(semantic.RPCLatency, cwmetric.NewOtelRecorder(serverDurationMeasure)),
 				cwmetric.WithRecorder(semantic.RPCRetry, cwmetric.NewOtelRecorder(serverRetryMeasure)),
+				cwmetric.WithRecorder(semantic.RPCRequestsPerRPC, cwmetric.NewOtelRecorder(requestsPerRPCMeasure)),
+				cwmetric.WithRecorder(semantic.RPCResponsesPerRPC, cwmetric.NewOtelRecorder(responsesPerRPCMeasure)),
+				cwmetric.WithRecorder(semantic.RPCStreamDuration, cwmetric.NewOtelRecorder(streamDurationMeasure)),
+				cwmetric.WithRecorder(semantic.RPCRequestSize, cwmetric.NewOtelRecorder(requestSizeMeasure)),
+				cwmetric.WithRecorder(semantic.RPCResponseSize, cwmetric.NewOtelRecorder(responseSizeMeasure)),
+				cwmetric.WithCounter(semantic.RPCRetryAttempt, cwmetric.NewOtelCounter(retryAttemptMeasure)),
+				cwmetric.WithRecorder(semantic.RPCConnectDuration, transportRecorder(semantic.ServerConnectDuration, "measures the duration of the connection establishment of the client")),
+				cwmetric.WithRecorder(semantic.RPCWriteDuration, transportRecorder(semantic.ServerWriteDuration, "measures the duration of the encoding and writing of the messages")),
+				cwmetric.WithRecorder(semantic.RPCReadDuration, transportRecorder(semantic.ServerReadDuration, "measures the duration of the waiting and reading of the messages")),
+				cwmetric.WithRecorder(semantic.RPCHandleDuration, transportRecorder(semantic.ServerHandleDuration, "measures the duration of the server handler")),
+				cwmetric.WithCounter(semantic.RPCInFlight, cwmetric.NewOtelUpDownCounter(inFlightMeasure)),
+				cwmetric.WithRecorder(semantic.RPCQueueWait, cwmetric.NewOtelRecorder(queueWaitMeasure)),
+				cwmetric.WithCounter(semantic.RPCLimitRejection, cwmetric.NewOtelCounter(limitRejectionMeasure)),
+				cwmetric.WithRecorder(semantic.RPCResolveLatency, cwmetric.NewOtelRecorder(resolveDurationMeasure)),
+				cwmetric.WithRecorder(semantic.RPCInstanceCount, cwmetric.NewOtelGaugeRecorder(instancesMeasure)),
+				cwmetric.WithCounter(semantic.RPCInstancePick, cwmetric.NewOtelCounter(instancePickMeasure)),
 			)
 		}
 		if cfg.enableHTTP {
@@ -209,9 +394,23 @@ func NewOpenTelemetryProvider(opts ...Option) provider.Provider {
 				otelmetric.WithDescription("measures th incoming end to end duration"),
 			)
 			HandleErr(err)
+			requestSizeMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("http", cfg.instanceType, semantic.ServerRequestSize),
+				otelmetric.WithUnit("By"),
+				otelmetric.WithDescription("measures size of HTTP request bodies"),
+			)
+			HandleErr(err)
+			responseSizeMeasure, err := meter.Float64Histogram(
+				semantic.BuildMetricName("http", cfg.instanceType, semantic.ServerResponseSize),
+				otelmetric.WithUnit("By"),
+				otelmetric.WithDescription("measures size of HTTP response bodies"),
+			)
+			HandleErr(err)
 			metrics = append(metrics,
 				cwmetric.WithCounter(semantic.HTTPCounter, cwmetric.NewOtelCounter(serverRequestCountMeasure)),
 				cwmetric.WithRecorder(semantic.HTTPLatency, cwmetric.NewOtelRecorder(serverLatencyMeasure)),
+				cwmetric.WithRecorder(semantic.HTTPRequestSize, cwmetric.NewOtelRecorder(requestSizeMeasure)),
+				cwmetric.WithRecorder(semantic.HTTPResponseSize, cwmetric.NewOtelRecorder(responseSizeMeasure)),
 			)
 		}
 
@@ -219,8 +418,7 @@ func NewOpenTelemetryProvider(opts ...Option) provider.Provider {
 
 		global.SetTracerMeasure(measure)
 
-		err = runtimemetrics.Start()
-		if err != nil {
+		if err = startCollectors(cfg, meterProvider); err != nil {
 			if cfg.enableHTTP {
 				handleInitErrh(err, "Failed to start runtime meter collector")
 			}
@@ -232,8 +430,14 @@ func NewOpenTelemetryProvider(opts ...Option) provider.Provider {
 	}
 
 	return &otelProvider{
-		traceExp:      traceExp,
-		metricsPusher: meterProvider,
+		traceExp:       traceExp,
+		tracerProvider: tracerProvider,
+		metricsPusher:  meterProvider,
+		metricReader:   metricReader,
+		measure:        measure,
+		traceStatus:    traceStatus,
+		metricStatus:   metricStatus,
+		queuedSpans:    queuedSpans,
 	}
 }
 
@@ -15,11 +15,21 @@
 package otelprovider
 
 import (
+	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider"
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	semconv140 "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
@@ -78,3 +88,229 @@ func Test_newResource(t *testing.T) {
 		})
 	}
 }
+
+func Test_startCollectors(t *testing.T) {
+	reader := metric.NewManualReader()
+	meterProvider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer meterProvider.Shutdown(context.Background()) //nolint:errcheck
+	defer stopCollectors(meterProvider)
+
+	cfg := newConfig([]Option{
+		WithRuntimeMetricsReadInterval(time.Second),
+		WithEnableProcessMetrics(true),
+	})
+
+	// starting twice must not register the collectors twice
+	assert.Nil(t, startCollectors(cfg, meterProvider))
+	assert.Nil(t, startCollectors(cfg, meterProvider))
+
+	var rm metricdata.ResourceMetrics
+	assert.Nil(t, reader.Collect(context.Background(), &rm))
+
+	scopes := map[string]int{}
+	for _, sm := range rm.ScopeMetrics {
+		scopes[sm.Scope.Name]++
+		if sm.Scope.Name == instrumentationNameProcess {
+			var names []string
+			for _, m := range sm.Metrics {
+				names = append(names, m.Name)
+			}
+			assert.Contains(t, names, "process.memory.rss")
+		}
+	}
+	assert.Equal(t, 1, scopes["go.opentelemetry.io/contrib/instrumentation/runtime"])
+	assert.Equal(t, 1, scopes[instrumentationNameProcess])
+}
+
+func collectedScopes(t *testing.T, reader metric.Reader) []string {
+	var rm metricdata.ResourceMetrics
+	assert.Nil(t, reader.Collect(context.Background(), &rm))
+	var scopes []string
+	for _, sm := range rm.ScopeMetrics {
+		scopes = append(scopes, sm.Scope.Name)
+	}
+	return scopes
+}
+
+func Test_startCollectorsOncePerProcess(t *testing.T) {
+	cfg := newConfig([]Option{WithEnableRuntimeMetrics(false), WithEnableProcessMetrics(true)})
+	oldReader, newReader := metric.NewManualReader(), metric.NewManualReader()
+	oldProvider := metric.NewMeterProvider(metric.WithReader(oldReader))
+	newProvider := metric.NewMeterProvider(metric.WithReader(newReader))
+	defer stopCollectors(newProvider)
+
+	assert.Nil(t, startCollectors(cfg, oldProvider))
+	assert.Nil(t, startCollectors(cfg, newProvider))
+	assert.Contains(t, collectedScopes(t, oldReader), instrumentationNameProcess)
+	assert.NotContains(t, collectedScopes(t, newReader), instrumentationNameProcess)
+
+	// the collectors move to the live provider when their owner is shut down
+	assert.Nil(t, oldProvider.Shutdown(context.Background()))
+	stopCollectors(oldProvider)
+	assert.Contains(t, collectedScopes(t, newReader), instrumentationNameProcess)
+}
+
+func Test_startCollectorRetriedAfterError(t *testing.T) {
+	meterProvider := metric.NewMeterProvider()
+	defer stopCollectors(meterProvider)
+
+	starts := 0
+	startFunc := startCollectorFunc
+	defer func() { startCollectorFunc = startFunc }()
+	startCollectorFunc = func(*config, otelmetric.MeterProvider, collector) func() error {
+		return func() error {
+			starts++
+			if starts == 1 {
+				return errors.New("start failed")
+			}
+			return nil
+		}
+	}
+
+	cfg := newConfig([]Option{WithEnableRuntimeMetrics(false), WithEnableHostMetrics(true)})
+	assert.EqualError(t, startCollectors(cfg, meterProvider), "start failed")
+	assert.Nil(t, startCollectors(cfg, meterProvider))
+	assert.Nil(t, startCollectors(cfg, meterProvider))
+	assert.Equal(t, 2, starts)
+}
+
+func Test_NewOpenTelemetryProviderStartsCollectorsOnce(t *testing.T) {
+	// shutdown does not wait for the final export, no collector listens on the endpoint
+	shutdown := func(p provider.Provider) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_ = p.Shutdown(ctx)
+	}
+
+	first := NewOpenTelemetryProvider(WithEnableTracing(false), WithInsecure()).(*otelProvider)
+	second := NewOpenTelemetryProvider(WithEnableTracing(false), WithInsecure()).(*otelProvider)
+	defer shutdown(second)
+
+	collectorsMu.Lock()
+	owner := collectorOwners[runtimeCollector]
+	collectorsMu.Unlock()
+	assert.Equal(t, otelmetric.MeterProvider(first.metricsPusher), owner)
+
+	shutdown(first)
+	collectorsMu.Lock()
+	owner = collectorOwners[runtimeCollector]
+	collectorsMu.Unlock()
+	assert.Equal(t, otelmetric.MeterProvider(second.metricsPusher), owner)
+}
+
+func Test_otelProviderForceFlushAndStatus(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	p := &otelProvider{
+		traceStatus: &provider.ExportStatus{},
+		queuedSpans: &atomic.Int64{},
+	}
+	p.tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(&queueCountingSpanProcessor{
+			SpanProcessor: sdktrace.NewBatchSpanProcessor(&statusSpanExporter{
+				SpanExporter: exporter,
+				status:       p.traceStatus,
+				queued:       p.queuedSpans,
+			}, sdktrace.WithBatchTimeout(time.Hour)),
+			queued: p.queuedSpans,
+		}),
+	)
+	defer p.tracerProvider.Shutdown(context.Background()) //nolint:errcheck
+
+	for i := 0; i < 3; i++ {
+		_, span := p.tracerProvider.Tracer("test").Start(context.Background(), "test-span")
+		span.End()
+	}
+
+	status, ok := provider.GetStatus(p)
+	assert.True(t, ok)
+	assert.Equal(t, 3, status.QueueDepth)
+	assert.True(t, status.LastExportTime.IsZero())
+
+	assert.Nil(t, provider.ForceFlush(context.Background(), p))
+	assert.Len(t, exporter.GetSpans(), 3)
+
+	status = p.Status()
+	assert.Equal(t, 0, status.QueueDepth)
+	assert.Nil(t, status.LastExportError)
+	assert.False(t, status.LastExportTime.IsZero())
+}
+
+type failingSpanExporter struct {
+	sdktrace.SpanExporter
+}
+
+func (failingSpanExporter) ExportSpans(context.Context, []sdktrace.ReadOnlySpan) error {
+	return errors.New("trace export failed")
+}
+
+func Test_otelProviderStatusPerPipeline(t *testing.T) {
+	p := &otelProvider{
+		traceStatus:  &provider.ExportStatus{},
+		metricStatus: &provider.ExportStatus{},
+		queuedSpans:  &atomic.Int64{},
+	}
+	spanExporter := &statusSpanExporter{
+		SpanExporter: failingSpanExporter{tracetest.NewInMemoryExporter()},
+		status:       p.traceStatus,
+		queued:       p.queuedSpans,
+	}
+	metricExporter := &statusMetricExporter{Exporter: &countingMetricExporter{}, status: p.metricStatus}
+
+	// the traces keep failing while the metrics are exported
+	assert.NotNil(t, spanExporter.ExportSpans(context.Background(), nil))
+	assert.Nil(t, metricExporter.Export(context.Background(), &metricdata.ResourceMetrics{}))
+
+	status := p.Status()
+	assert.EqualError(t, status.LastExportError, "trace export failed")
+	assert.True(t, status.LastExportTime.IsZero())
+
+	// a metric provider given with WithMeterProvider is not tracked
+	p.metricStatus = nil
+	assert.EqualError(t, p.Status().LastExportError, "trace export failed")
+}
+
+type countingMetricExporter struct {
+	exports atomic.Int64
+}
+
+func (e *countingMetricExporter) Temporality(kind metric.InstrumentKind) metricdata.Temporality {
+	return metric.DefaultTemporalitySelector(kind)
+}
+
+func (e *countingMetricExporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
+	return metric.DefaultAggregationSelector(kind)
+}
+
+func (e *countingMetricExporter) Export(context.Context, *metricdata.ResourceMetrics) error {
+	e.exports.Add(1)
+	return nil
+}
+
+func (e *countingMetricExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (e *countingMetricExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func Test_intervalReader(t *testing.T) {
+	exporter := &countingMetricExporter{}
+	reader := newIntervalReader(exporter, time.Hour)
+	meterProvider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	assert.Nil(t, meterProvider.ForceFlush(context.Background()))
+	assert.Equal(t, int64(1), exporter.exports.Load())
+
+	// the new interval applies without waiting for the old one
+	reader.SetInterval(10 * time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return exporter.exports.Load() > 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, meterProvider.Shutdown(context.Background()))
+	exports := exporter.exports.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, exports, exporter.exports.Load())
+	assert.ErrorIs(t, reader.Shutdown(context.Background()), metric.ErrReaderShutdown)
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelprovider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+var _ metric.Reader = &intervalReader{}
+
+// intervalReader collects and exports metrics periodically like metric.PeriodicReader,
+// but its interval can be changed while it is running.
+type intervalReader struct {
+	*metric.ManualReader
+
+	exporter metric.Exporter
+	interval atomic.Int64
+
+	// exportMu serializes exports so that the exporter is never called concurrently
+	exportMu sync.Mutex
+
+	reset    chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newIntervalReader(exporter metric.Exporter, interval time.Duration) *intervalReader {
+	r := &intervalReader{
+		ManualReader: metric.NewManualReader(
+			metric.WithTemporalitySelector(exporter.Temporality),
+			metric.WithAggregationSelector(exporter.Aggregation),
+		),
+		exporter: exporter,
+		reset:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	r.interval.Store(int64(interval))
+
+	go r.run()
+
+	return r
+}
+
+// SetInterval changes the export interval, the next export happens one new interval from now
+func (r *intervalReader) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	r.interval.Store(int64(interval))
+	select {
+	case r.reset <- struct{}{}:
+	default:
+	}
+}
+
+func (r *intervalReader) run() {
+	defer close(r.stopped)
+
+	timer := time.NewTimer(time.Duration(r.interval.Load()))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-r.reset:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-timer.C:
+			if err := r.collectAndExport(context.Background()); err != nil {
+				otel.Handle(err)
+			}
+		}
+		timer.Reset(time.Duration(r.interval.Load()))
+	}
+}
+
+func (r *intervalReader) collectAndExport(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(r.interval.Load()))
+	defer cancel()
+
+	r.exportMu.Lock()
+	defer r.exportMu.Unlock()
+
+	var rm metricdata.ResourceMetrics
+	if err := r.ManualReader.Collect(ctx, &rm); err != nil {
+		return err
+	}
+	return r.exporter.Export(ctx, &rm)
+}
+
+// ForceFlush exports the metrics collected so far and flushes the exporter
+func (r *intervalReader) ForceFlush(ctx context.Context) error {
+	return errors.Join(r.collectAndExport(ctx), r.exporter.ForceFlush(ctx))
+}
+
+// Shutdown stops the export loop, exports the pending metrics and shuts down the exporter
+func (r *intervalReader) Shutdown(ctx context.Context) error {
+	err := metric.ErrReaderShutdown
+	r.stopOnce.Do(func() {
+		close(r.done)
+		<-r.stopped
+		err = errors.Join(
+			r.collectAndExport(ctx),
+			r.ManualReader.Shutdown(ctx),
+			r.exporter.Shutdown(ctx),
+		)
+	})
+	return err
+}
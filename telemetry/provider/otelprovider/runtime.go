@@ -0,0 +1,224 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelprovider
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/shirou/gopsutil/v4/process"
+	"go.opentelemetry.io/contrib/instrumentation/host"
+	runtimemetrics "go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel"
+	otelmetric "go.opentelemetry.io/otel/metric"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+)
+
+const instrumentationNameProcess = "github.com/cloudwego-contrib/telemetry-opentelemetry/process"
+
+type collector int
+
+const (
+	runtimeCollector collector = iota
+	hostCollector
+	processCollector
+)
+
+// collectors lists the collectors in start order
+var collectors = []collector{runtimeCollector, hostCollector, processCollector}
+
+// collectorRequest is a live provider which enabled some collectors
+type collectorRequest struct {
+	cfg           *config
+	meterProvider otelmetric.MeterProvider
+}
+
+// runtime, host and process instruments are registered against a MeterProvider and keep
+// collecting for its lifetime, every provider building its own MeterProvider would export the
+// same series again. Each collector is therefore started once per process against the
+// MeterProvider of one provider, its owner. When the owner is shut down the collector is started
+// again against the newest live provider which enabled it. A failed start is retried by the next
+// provider.
+var (
+	collectorsMu      sync.Mutex
+	collectorOwners   = map[collector]otelmetric.MeterProvider{}
+	collectorRequests []collectorRequest
+)
+
+// startCollectors starts the runtime, host and process metric collectors enabled in cfg
+// which are not started by another provider yet
+func startCollectors(cfg *config, meterProvider otelmetric.MeterProvider) error {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+
+	if !collectorRequested(meterProvider) {
+		collectorRequests = append(collectorRequests, collectorRequest{cfg: cfg, meterProvider: meterProvider})
+	}
+	for _, c := range collectors {
+		if !collectorEnabled(cfg, c) {
+			continue
+		}
+		if err := startCollector(cfg, meterProvider, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startCollector starts the collector against meterProvider unless it already has an owner,
+// collectorsMu must be held
+func startCollector(cfg *config, meterProvider otelmetric.MeterProvider, c collector) error {
+	if _, ok := collectorOwners[c]; ok {
+		return nil
+	}
+	if err := startCollectorFunc(cfg, meterProvider, c)(); err != nil {
+		return err
+	}
+	collectorOwners[c] = meterProvider
+	return nil
+}
+
+// stopCollectors releases the collectors of a MeterProvider which is shut down and starts them
+// against the newest live provider which enabled them
+func stopCollectors(meterProvider otelmetric.MeterProvider) {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+
+	requests := collectorRequests[:0]
+	for _, req := range collectorRequests {
+		if req.meterProvider != meterProvider {
+			requests = append(requests, req)
+		}
+	}
+	collectorRequests = requests
+
+	for _, c := range collectors {
+		if owner, ok := collectorOwners[c]; !ok || owner != meterProvider {
+			continue
+		}
+		delete(collectorOwners, c)
+		for i := len(collectorRequests) - 1; i >= 0; i-- {
+			req := collectorRequests[i]
+			if !collectorEnabled(req.cfg, c) {
+				continue
+			}
+			err := startCollector(req.cfg, req.meterProvider, c)
+			if err == nil {
+				break
+			}
+			otel.Handle(err)
+		}
+	}
+}
+
+func collectorRequested(meterProvider otelmetric.MeterProvider) bool {
+	for _, req := range collectorRequests {
+		if req.meterProvider == meterProvider {
+			return true
+		}
+	}
+	return false
+}
+
+func collectorEnabled(cfg *config, c collector) bool {
+	switch c {
+	case runtimeCollector:
+		return cfg.enableRuntimeMetrics
+	case hostCollector:
+		return cfg.enableHostMetrics
+	case processCollector:
+		return cfg.enableProcessMetrics
+	}
+	return false
+}
+
+// startCollectorFunc returns the function starting the collector against meterProvider,
+// it is replaced by the tests to fail the starts
+var startCollectorFunc = func(cfg *config, meterProvider otelmetric.MeterProvider, c collector) func() error {
+	switch c {
+	case runtimeCollector:
+		return func() error {
+			opts := []runtimemetrics.Option{runtimemetrics.WithMeterProvider(meterProvider)}
+			if cfg.runtimeMetricsReadInterval > 0 {
+				opts = append(opts, runtimemetrics.WithMinimumReadMemStatsInterval(cfg.runtimeMetricsReadInterval))
+			}
+			return runtimemetrics.Start(opts...)
+		}
+	case hostCollector:
+		return func() error {
+			return host.Start(host.WithMeterProvider(meterProvider))
+		}
+	default:
+		return func() error {
+			return startProcessMetrics(meterProvider)
+		}
+	}
+}
+
+// startProcessMetrics registers cpu time, resident memory and open file descriptors of the current process
+func startProcessMetrics(meterProvider otelmetric.MeterProvider) error {
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return err
+	}
+
+	meter := meterProvider.Meter(
+		instrumentationNameProcess,
+		otelmetric.WithInstrumentationVersion(semantic.SemVersion()),
+	)
+
+	cpuTime, err := meter.Float64ObservableCounter(
+		"process.cpu.time",
+		otelmetric.WithUnit("s"),
+		otelmetric.WithDescription("Accumulated CPU time spent by this process"),
+	)
+	if err != nil {
+		return err
+	}
+	memoryRSS, err := meter.Int64ObservableGauge(
+		"process.memory.rss",
+		otelmetric.WithUnit("By"),
+		otelmetric.WithDescription("Resident set size of this process"),
+	)
+	if err != nil {
+		return err
+	}
+	openFDs, err := meter.Int64ObservableGauge(
+		"process.open_file_descriptors",
+		otelmetric.WithUnit("{fd}"),
+		otelmetric.WithDescription("Number of file descriptors opened by this process"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o otelmetric.Observer) error {
+		if times, err := proc.TimesWithContext(ctx); err == nil {
+			o.ObserveFloat64(cpuTime, times.User+times.System)
+		}
+		if mem, err := proc.MemoryInfoWithContext(ctx); err == nil {
+			o.ObserveInt64(memoryRSS, int64(mem.RSS))
+		}
+		// not every platform is able to report open file descriptors
+		if fds, err := proc.NumFDsWithContext(ctx); err == nil {
+			o.ObserveInt64(openFDs, int64(fds))
+		}
+		return nil
+	}, cpuTime, memoryRSS, openFDs)
+
+	return err
+}
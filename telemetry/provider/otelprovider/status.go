@@ -0,0 +1,81 @@
+// Copyright 2022 CloudWeGo Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelprovider
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider"
+)
+
+var (
+	_ sdktrace.SpanExporter  = &statusSpanExporter{}
+	_ sdktrace.SpanProcessor = &queueCountingSpanProcessor{}
+	_ metric.Exporter        = &statusMetricExporter{}
+)
+
+// statusSpanExporter records the result of every span export
+type statusSpanExporter struct {
+	sdktrace.SpanExporter
+	status *provider.ExportStatus
+	queued *atomic.Int64
+}
+
+func (e *statusSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.queued.Add(-int64(len(spans)))
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.status.Record(err)
+	return err
+}
+
+// queueCountingSpanProcessor counts the sampled spans handed to the batch processor, together with
+// statusSpanExporter it gives the number of spans waiting to be exported.
+// Spans dropped by a full queue are not exported, so the count is reset after a successful flush.
+type queueCountingSpanProcessor struct {
+	sdktrace.SpanProcessor
+	queued *atomic.Int64
+}
+
+func (p *queueCountingSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.queued.Add(1)
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+func (p *queueCountingSpanProcessor) ForceFlush(ctx context.Context) error {
+	err := p.SpanProcessor.ForceFlush(ctx)
+	if err == nil {
+		p.queued.Store(0)
+	}
+	return err
+}
+
+// statusMetricExporter records the result of every metric export
+type statusMetricExporter struct {
+	metric.Exporter
+	status *provider.ExportStatus
+}
+
+func (e *statusMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	e.status.Record(err)
+	return err
+}
@@ -23,6 +23,8 @@ import (
 var (
 	defaultBuckets = []float64{5000, 10000, 25000, 50000, 100000, 250000, 500000, 1000000}
 	retryBuckets   = []float64{0, 5, 10, 50, 100, 1000, 5000, 10000, 50000}
+	messageBuckets = []float64{1, 2, 5, 10, 20, 50, 100, 500, 1000, 5000}
+	sizeBuckets    = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304}
 )
 
 // Option opts for opentelemetry tracer provider
@@ -43,6 +45,9 @@ type config struct {
 	name       string
 	enableRPC  bool
 	enableHTTP bool
+
+	rpcExtraLabels  []string
+	httpExtraLabels []string
 }
 
 func newConfig(opts []Option) *config {
@@ -99,3 +104,19 @@ func WithServiceName(name string) Option {
 		cfg.name = name
 	})
 }
+
+// WithRPCExtraLabels declares the labels added to the rpc metrics by otelkitex,
+// like the labels of a label func, the baggage metric labels or the caller peer labels
+func WithRPCExtraLabels(names ...string) Option {
+	return option(func(cfg *config) {
+		cfg.rpcExtraLabels = append(cfg.rpcExtraLabels, names...)
+	})
+}
+
+// WithHTTPExtraLabels declares the labels added to the http metrics by otelhertz,
+// like the labels of a label func or the baggage metric labels
+func WithHTTPExtraLabels(names ...string) Option {
+	return option(func(cfg *config) {
+		cfg.httpExtraLabels = append(cfg.httpExtraLabels, names...)
+	})
+}
@@ -30,13 +30,27 @@ import (
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider"
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
-var _ provider.Provider = &promProvider{}
+var (
+	_ provider.Provider        = &promProvider{}
+	_ provider.MeasureProvider = &promProvider{}
+	_ provider.Flusher         = &promProvider{}
+	_ provider.StatusReporter  = &promProvider{}
+)
 
 // promProvider Structure of promProvider, including Prometheus registry and HTTP server
 type promProvider struct {
 	registry *prometheus.Registry
+	measure  metric.Measure
+
+	scrapeStatus *provider.ExportStatus
+}
+
+// Measure returns the measure backed by the prometheus registry
+func (p *promProvider) Measure() metric.Measure {
+	return p.measure
 }
 
 // Shutdown Implement the Shutdown method for the Provider interface
@@ -45,6 +59,25 @@ func (p *promProvider) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// ForceFlush Implement the Flusher interface, metrics are pulled by the prometheus server so there is nothing to flush
+func (p *promProvider) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Status Implement the StatusReporter interface, an export is a scrape of the metrics endpoint
+func (p *promProvider) Status() provider.Status {
+	return p.scrapeStatus.Status(0)
+}
+
+// rpcLabelNames are the labels of the rpc metrics recorded by otelkitex
+var rpcLabelNames = []string{
+	semantic.LabelRPCCallerKey, semantic.LabelRPCCalleeKey, semantic.LabelRPCMethodKey,
+	semantic.LabelKeyStatus, semantic.LabelKeyErrorType,
+}
+
+// concurrencyLabelNames are the labels of the concurrency metrics of the servers
+var concurrencyLabelNames = []string{semantic.LabelRPCCalleeKey, semantic.LabelRPCMethodKey}
+
 // NewPromProvider Initialize and return a new promProvider instance
 func NewPromProvider(opts ...Option) *promProvider {
 	cfg := newConfig(opts)
@@ -54,13 +87,15 @@ func NewPromProvider(opts ...Option) *promProvider {
 	}
 	var measure metric.Measure
 	var metrics []metric.Option
+	rpcLabels := append(append([]string{}, rpcLabelNames...), cfg.rpcExtraLabels...)
+	httpLabels := append([]string{semantic.LabelHttpMethodKey, semantic.LabelStatusCode, semantic.LabelPath}, cfg.httpExtraLabels...)
 	if cfg.enableRPC {
 		RPCCounterVec := prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: buildName(cfg.name, "rpc", semantic.Counter),
 				Help: fmt.Sprintf("Total number of requires completed by the %s, regardless of success or failure.", semantic.Counter),
 			},
-			[]string{semantic.LabelRPCCallerKey, semantic.LabelRPCCalleeKey, semantic.LabelRPCMethodKey, semantic.LabelKeyStatus},
+			rpcLabels,
 		)
 		registry.MustRegister(RPCCounterVec)
 		counter := metric.NewPromCounter(RPCCounterVec)
@@ -71,7 +106,7 @@ func NewPromProvider(opts ...Option) *promProvider {
 				Help:    fmt.Sprintf("Latency (microseconds) of the %s until it is finished.", semantic.Latency),
 				Buckets: cfg.buckets,
 			},
-			[]string{semantic.LabelRPCCallerKey, semantic.LabelRPCCalleeKey, semantic.LabelRPCMethodKey, semantic.LabelKeyStatus},
+			rpcLabels,
 		)
 		registry.MustRegister(clientHandledHistogramRPC)
 		recorder := metric.NewPromRecorder(clientHandledHistogramRPC)
@@ -87,10 +122,153 @@ func NewPromProvider(opts ...Option) *promProvider {
 		registry.MustRegister(retryHandledHistogramRPC)
 		retryRecorder := metric.NewPromRecorder(retryHandledHistogramRPC)
 
+		// create streaming recorders
+		requestsPerRPCHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "rpc", semantic.ServerRequestsPerRPC),
+				Help:    "Distribution of the number of messages received per streaming RPC.",
+				Buckets: messageBuckets,
+			},
+			rpcLabels,
+		)
+		registry.MustRegister(requestsPerRPCHistogram)
+		responsesPerRPCHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "rpc", semantic.ServerResponsesPerRPC),
+				Help:    "Distribution of the number of messages sent per streaming RPC.",
+				Buckets: messageBuckets,
+			},
+			rpcLabels,
+		)
+		registry.MustRegister(responsesPerRPCHistogram)
+		streamDurationHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "rpc", semantic.ServerStreamDuration),
+				Help:    "Duration (milliseconds) of the streaming RPC until the stream is finished.",
+				Buckets: cfg.buckets,
+			},
+			rpcLabels,
+		)
+		registry.MustRegister(streamDurationHistogram)
+
+		// create size recorders
+		requestSizeHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "rpc", semantic.RequestSize),
+				Help:    "Size (bytes) of the RPC requests.",
+				Buckets: sizeBuckets,
+			},
+			rpcLabels,
+		)
+		registry.MustRegister(requestSizeHistogram)
+		responseSizeHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "rpc", semantic.ResponseSize),
+				Help:    "Size (bytes) of the RPC responses.",
+				Buckets: sizeBuckets,
+			},
+			rpcLabels,
+		)
+		registry.MustRegister(responseSizeHistogram)
+
+		// create retry attempt counter
+		retryAttemptCounterVec := prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: buildName(cfg.name, "rpc", semantic.RetryAttempt),
+				Help: "Total number of attempts of the retried calls by outcome.",
+			},
+			[]string{semantic.LabelRPCCallerKey, semantic.LabelRPCCalleeKey, semantic.LabelRPCMethodKey, semantic.LabelKeyAttempt, semantic.LabelKeyBackup, semantic.LabelKeyStatus},
+		)
+		registry.MustRegister(retryAttemptCounterVec)
+
+		// create transport recorders
+		transportRecorder := func(name, help string) metric.Recorder {
+			histogram := prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Name:    buildName(cfg.name, "rpc", name),
+					Help:    help,
+					Buckets: cfg.buckets,
+				},
+				rpcLabels,
+			)
+			registry.MustRegister(histogram)
+			return metric.NewPromRecorder(histogram)
+		}
+
+		// create concurrency metrics
+		inFlightGaugeVec := prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: buildName(cfg.name, "rpc", semantic.InFlight),
+				Help: "Number of requests currently handled by the server.",
+			},
+			concurrencyLabelNames,
+		)
+		registry.MustRegister(inFlightGaugeVec)
+		queueWaitHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "rpc", semantic.QueueWait),
+				Help:    "Duration (milliseconds) from the decoding of the requests to the start of the handler.",
+				Buckets: cfg.buckets,
+			},
+			concurrencyLabelNames,
+		)
+		registry.MustRegister(queueWaitHistogram)
+		limitRejectionCounterVec := prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: buildName(cfg.name, "rpc", semantic.LimitRejection),
+				Help: "Total number of requests rejected by the server limiter.",
+			},
+			[]string{semantic.LabelKeyLimit},
+		)
+		registry.MustRegister(limitRejectionCounterVec)
+
+		// create service discovery metrics
+		resolveDurationHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "rpc", semantic.ResolveDuration),
+				Help:    "Duration (milliseconds) of the service discovery lookups.",
+				Buckets: cfg.buckets,
+			},
+			[]string{semantic.LabelKeyResolver, semantic.LabelKeyTarget, semantic.LabelKeyStatus},
+		)
+		registry.MustRegister(resolveDurationHistogram)
+		instancesGaugeVec := prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: buildName(cfg.name, "rpc", semantic.Instances),
+				Help: "Number of instances resolved for a target by the service discovery.",
+			},
+			[]string{semantic.LabelKeyResolver, semantic.LabelKeyTarget},
+		)
+		registry.MustRegister(instancesGaugeVec)
+		instancePickCounterVec := prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: buildName(cfg.name, "rpc", semantic.InstancePick),
+				Help: "Total number of instances picked by the load balancer.",
+			},
+			[]string{semantic.LabelRPCCalleeKey, semantic.LabelKeyBalancer, semantic.LabelKeyInstance},
+		)
+		registry.MustRegister(instancePickCounterVec)
+
 		metrics = append(metrics,
 			metric.WithCounter(semantic.RPCCounter, counter),
 			metric.WithRecorder(semantic.RPCLatency, recorder),
 			metric.WithRecorder(semantic.RPCRetry, retryRecorder),
+			metric.WithRecorder(semantic.RPCRequestsPerRPC, metric.NewPromRecorder(requestsPerRPCHistogram)),
+			metric.WithRecorder(semantic.RPCResponsesPerRPC, metric.NewPromRecorder(responsesPerRPCHistogram)),
+			metric.WithRecorder(semantic.RPCStreamDuration, metric.NewPromRecorder(streamDurationHistogram)),
+			metric.WithRecorder(semantic.RPCRequestSize, metric.NewPromRecorder(requestSizeHistogram)),
+			metric.WithRecorder(semantic.RPCResponseSize, metric.NewPromRecorder(responseSizeHistogram)),
+			metric.WithCounter(semantic.RPCRetryAttempt, metric.NewPromCounter(retryAttemptCounterVec)),
+			metric.WithRecorder(semantic.RPCConnectDuration, transportRecorder(semantic.ConnectDuration, "Duration (milliseconds) of the connection establishment of the client.")),
+			metric.WithRecorder(semantic.RPCWriteDuration, transportRecorder(semantic.WriteDuration, "Duration (milliseconds) of the encoding and writing of the messages.")),
+			metric.WithRecorder(semantic.RPCReadDuration, transportRecorder(semantic.ReadDuration, "Duration (milliseconds) of the waiting and reading of the messages.")),
+			metric.WithRecorder(semantic.RPCHandleDuration, transportRecorder(semantic.HandleDuration, "Duration (milliseconds) of the server handler.")),
+			metric.WithCounter(semantic.RPCInFlight, metric.NewPromGauge(inFlightGaugeVec)),
+			metric.WithRecorder(semantic.RPCQueueWait, metric.NewPromRecorder(queueWaitHistogram)),
+			metric.WithCounter(semantic.RPCLimitRejection, metric.NewPromCounter(limitRejectionCounterVec)),
+			metric.WithRecorder(semantic.RPCResolveLatency, metric.NewPromRecorder(resolveDurationHistogram)),
+			metric.WithRecorder(semantic.RPCInstanceCount, metric.NewPromGaugeRecorder(instancesGaugeVec)),
+			metric.WithCounter(semantic.RPCInstancePick, metric.NewPromCounter(instancePickCounterVec)),
 		)
 	}
 	if cfg.enableHTTP {
@@ -99,7 +277,7 @@ func NewPromProvider(opts ...Option) *promProvider {
 				Name: buildName(cfg.name, "http", semantic.Counter),
 				Help: "Total number of HTTPs completed by the server, regardless of success or failure.",
 			},
-			[]string{semantic.LabelHttpMethodKey, semantic.LabelStatusCode, semantic.LabelPath},
+			httpLabels,
 		)
 		registry.MustRegister(HttpCounterVec)
 		counter := metric.NewPromCounter(HttpCounterVec)
@@ -110,15 +288,37 @@ func NewPromProvider(opts ...Option) *promProvider {
 				Help:    "Latency (microseconds) of HTTP that had been application-level handled by the server.",
 				Buckets: cfg.buckets,
 			},
-			[]string{semantic.LabelHttpMethodKey, semantic.LabelStatusCode, semantic.LabelPath},
+			httpLabels,
 		)
 		registry.MustRegister(HttpHandledHistogram)
 
 		recorder := metric.NewPromRecorder(HttpHandledHistogram)
 
+		// create size recorders
+		httpRequestSizeHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "http", semantic.RequestSize),
+				Help:    "Size (bytes) of the HTTP request bodies.",
+				Buckets: sizeBuckets,
+			},
+			httpLabels,
+		)
+		registry.MustRegister(httpRequestSizeHistogram)
+		httpResponseSizeHistogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    buildName(cfg.name, "http", semantic.ResponseSize),
+				Help:    "Size (bytes) of the HTTP response bodies.",
+				Buckets: sizeBuckets,
+			},
+			httpLabels,
+		)
+		registry.MustRegister(httpResponseSizeHistogram)
+
 		metrics = append(metrics,
 			metric.WithCounter(semantic.HTTPCounter, counter),
 			metric.WithRecorder(semantic.HTTPLatency, recorder),
+			metric.WithRecorder(semantic.HTTPRequestSize, metric.NewPromRecorder(httpRequestSizeHistogram)),
+			metric.WithRecorder(semantic.HTTPResponseSize, metric.NewPromRecorder(httpResponseSizeHistogram)),
 		)
 	}
 
@@ -127,12 +327,14 @@ func NewPromProvider(opts ...Option) *promProvider {
 	global.SetTracerMeasure(measure)
 
 	return &promProvider{
-		registry: registry,
+		registry:     registry,
+		measure:      measure,
+		scrapeStatus: &provider.ExportStatus{},
 	}
 }
 
 func (p *promProvider) Serve(addr, path string) {
-	http.Handle(path, promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{
+	http.Handle(path, promhttp.HandlerFor(&statusGatherer{Gatherer: p.registry, status: p.scrapeStatus}, promhttp.HandlerOpts{
 		ErrorHandling: promhttp.ContinueOnError,
 	}))
 	go func() {
@@ -142,6 +344,18 @@ func (p *promProvider) Serve(addr, path string) {
 	}()
 }
 
+// statusGatherer records the result of every scrape
+type statusGatherer struct {
+	prometheus.Gatherer
+	status *provider.ExportStatus
+}
+
+func (g *statusGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	g.status.Record(err)
+	return mfs, err
+}
+
 func buildName(name, protocol, service string) string {
 	if name != "" {
 		return fmt.Sprintf("%s_%s_%s", name, protocol, service)
@@ -150,9 +364,24 @@ func buildName(name, protocol, service string) string {
 }
 
 func Server(addr, path string, p provider.Provider) {
-	if promProv, ok := p.(*promProvider); ok {
+	if promProv := findPromProvider(p); promProv != nil {
 		promProv.Serve(addr, path)
 	} else {
 		hlog.Info("HERTZ: Server should put promProvider")
 	}
 }
+
+// findPromProvider returns the promProvider itself or the first one grouped in a composite provider
+func findPromProvider(p provider.Provider) *promProvider {
+	if promProv, ok := p.(*promProvider); ok {
+		return promProv
+	}
+	if composite, ok := p.(provider.CompositeProvider); ok {
+		for _, sub := range composite.Providers() {
+			if promProv := findPromProvider(sub); promProv != nil {
+				return promProv
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package promprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+)
+
+func TestPromProviderExtraLabels(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := NewPromProvider(
+		WithRegistry(registry),
+		WithRPCServer(),
+		WithHttpServer(),
+		WithRPCExtraLabels(semantic.LabelRPCCallerNamespaceKey),
+		WithHTTPExtraLabels("tenant"),
+	)
+	ctx := context.Background()
+
+	// the rpc and http metrics only expect their own extra labels
+	assert.Nil(t, p.Measure().Inc(ctx, semantic.RPCCounter,
+		label.CwLabel{Key: semantic.LabelRPCCallerKey, Value: "caller"},
+		label.CwLabel{Key: semantic.LabelRPCCalleeKey, Value: "callee"},
+		label.CwLabel{Key: semantic.LabelRPCMethodKey, Value: "Echo"},
+		label.CwLabel{Key: semantic.LabelKeyStatus, Value: semantic.StatusSucceed},
+		label.CwLabel{Key: semantic.LabelKeyErrorType, Value: semantic.ErrorTypeNone},
+		label.CwLabel{Key: semantic.LabelRPCCallerNamespaceKey, Value: "ns"},
+	))
+	assert.Nil(t, p.Measure().Inc(ctx, semantic.HTTPCounter,
+		label.CwLabel{Key: semantic.LabelHttpMethodKey, Value: "GET"},
+		label.CwLabel{Key: semantic.LabelStatusCode, Value: "200"},
+		label.CwLabel{Key: semantic.LabelPath, Value: "/ping"},
+		label.CwLabel{Key: "tenant", Value: "t1"},
+	))
+
+	// an undeclared label is rejected
+	assert.NotNil(t, p.Measure().Inc(ctx, semantic.HTTPCounter,
+		label.CwLabel{Key: semantic.LabelHttpMethodKey, Value: "GET"},
+		label.CwLabel{Key: semantic.LabelStatusCode, Value: "200"},
+		label.CwLabel{Key: semantic.LabelPath, Value: "/ping"},
+		label.CwLabel{Key: semantic.LabelRPCCallerNamespaceKey, Value: "ns"},
+	))
+
+	count, err := testutil.GatherAndCount(registry, "rpc_counter", "http_counter")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
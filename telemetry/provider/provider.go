@@ -18,8 +18,90 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/metric"
 )
 
 type Provider interface {
 	Shutdown(ctx context.Context) error
 }
+
+// MeasureProvider is implemented by providers which record metrics through a metric.Measure
+type MeasureProvider interface {
+	Measure() metric.Measure
+}
+
+// CompositeProvider is implemented by providers grouping several providers together
+type CompositeProvider interface {
+	Providers() []Provider
+}
+
+// Flusher is implemented by providers able to export pending telemetry without shutting down
+type Flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// StatusReporter is implemented by providers reporting the health of their exports
+type StatusReporter interface {
+	Status() Status
+}
+
+// Status health report of a provider
+type Status struct {
+	// LastExportError is the error of the last failed export, nil if the last export succeeded
+	LastExportError error
+	// LastExportTime is the time of the last successful export
+	LastExportTime time.Time
+	// QueueDepth is the number of items waiting to be exported
+	QueueDepth int
+}
+
+// ErrFlushNotSupported is returned by ForceFlush when the provider can not be flushed
+var ErrFlushNotSupported = errors.New("provider does not support force flush")
+
+// ForceFlush flushes the provider if it implements Flusher
+func ForceFlush(ctx context.Context, p Provider) error {
+	if f, ok := p.(Flusher); ok {
+		return f.ForceFlush(ctx)
+	}
+	return ErrFlushNotSupported
+}
+
+// GetStatus returns the status of the provider if it implements StatusReporter
+func GetStatus(p Provider) (Status, bool) {
+	if r, ok := p.(StatusReporter); ok {
+		return r.Status(), true
+	}
+	return Status{}, false
+}
+
+// ExportStatus records the outcome of exports to build a Status, it is safe for concurrent use
+type ExportStatus struct {
+	mu             sync.RWMutex
+	lastExportErr  error
+	lastExportTime time.Time
+}
+
+// Record records the result of an export
+func (s *ExportStatus) Record(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastExportErr = err
+	if err == nil {
+		s.lastExportTime = time.Now()
+	}
+}
+
+// Status returns the recorded status with the given queue depth
+func (s *ExportStatus) Status(queueDepth int) Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Status{
+		LastExportError: s.lastExportErr,
+		LastExportTime:  s.lastExportTime,
+		QueueDepth:      queueDepth,
+	}
+}
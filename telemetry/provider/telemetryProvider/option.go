@@ -34,21 +34,36 @@ func (fn option) apply(cfg *config) {
 }
 
 type config struct {
-	provider provider2.Provider
+	providers []provider2.Provider
 }
 
+// WithOtel adds an opentelemetry provider
 func WithOtel(opts ...otelprovider.Option) Option {
 	return option(func(cfg *config) {
-		cfg.provider = otelprovider.NewOpenTelemetryProvider(opts...)
+		cfg.addProvider(otelprovider.NewOpenTelemetryProvider(opts...))
 	})
 }
 
+// WithProm adds a prometheus provider
 func WithProm(opts ...promprovider.Option) Option {
 	return option(func(cfg *config) {
-		cfg.provider = promprovider.NewPromProvider(opts...)
+		cfg.addProvider(promprovider.NewPromProvider(opts...))
 	})
 }
 
+// WithProvider adds a custom provider
+func WithProvider(p provider2.Provider) Option {
+	return option(func(cfg *config) {
+		cfg.addProvider(p)
+	})
+}
+
+func (cfg *config) addProvider(p provider2.Provider) {
+	if p != nil {
+		cfg.providers = append(cfg.providers, p)
+	}
+}
+
 func newConfig(opts []Option) *config {
 	cfg := &config{}
 
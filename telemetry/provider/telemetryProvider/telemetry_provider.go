@@ -18,22 +18,105 @@ package telemetryProvider
 
 import (
 	"context"
+	"errors"
 
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/global"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/metric"
 	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/provider"
 )
 
-var _ provider.Provider = &TelemetryProvider{}
+var (
+	_ provider.Provider          = &TelemetryProvider{}
+	_ provider.MeasureProvider   = &TelemetryProvider{}
+	_ provider.CompositeProvider = &TelemetryProvider{}
+	_ provider.Flusher           = &TelemetryProvider{}
+	_ provider.StatusReporter    = &TelemetryProvider{}
+)
 
+// TelemetryProvider runs several providers together, e.g. otlp tracing with a prometheus scrape endpoint
 type TelemetryProvider struct {
-	provider provider.Provider
+	providers []provider.Provider
+	measure   metric.Measure
 }
 
+// Shutdown shuts down all providers and returns their joined errors
 func (t TelemetryProvider) Shutdown(ctx context.Context) error {
-	return t.provider.Shutdown(ctx)
+	var errs []error
+	for _, p := range t.providers {
+		errs = append(errs, p.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// ForceFlush flushes all providers supporting it and returns their joined errors
+func (t TelemetryProvider) ForceFlush(ctx context.Context) error {
+	var errs []error
+	for _, p := range t.providers {
+		if f, ok := p.(provider.Flusher); ok {
+			errs = append(errs, f.ForceFlush(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Status aggregates the status of all providers reporting one: last export errors are joined,
+// the last export time is the oldest one so that a single stale backend is noticed,
+// and queue depths are summed.
+func (t TelemetryProvider) Status() provider.Status {
+	var (
+		status   provider.Status
+		errs     []error
+		reported bool
+	)
+	for _, p := range t.providers {
+		st, ok := provider.GetStatus(p)
+		if !ok {
+			continue
+		}
+		errs = append(errs, st.LastExportError)
+		if !reported || st.LastExportTime.Before(status.LastExportTime) {
+			status.LastExportTime = st.LastExportTime
+		}
+		reported = true
+		status.QueueDepth += st.QueueDepth
+	}
+	status.LastExportError = errors.Join(errs...)
+	return status
+}
+
+// Providers returns the providers grouped together
+func (t TelemetryProvider) Providers() []provider.Provider {
+	return t.providers
+}
+
+// Measure returns the measure combining the measures of all providers
+func (t TelemetryProvider) Measure() metric.Measure {
+	return t.measure
 }
 
 func NewTelemetryProvider(opts ...Option) provider.Provider {
 	cfg := newConfig(opts)
 
-	return &TelemetryProvider{cfg.provider}
+	// every provider replaces the global measure on creation, so combine them once all are created
+	var measures []metric.Measure
+	for _, p := range cfg.providers {
+		if mp, ok := p.(provider.MeasureProvider); ok && mp.Measure() != nil {
+			measures = append(measures, mp.Measure())
+		}
+	}
+
+	var measure metric.Measure
+	switch len(measures) {
+	case 0:
+	case 1:
+		measure = measures[0]
+	default:
+		measure = metric.NewMultiMeasure(measures...)
+		global.SetTracerMeasure(measure)
+	}
+
+	return &TelemetryProvider{
+		providers: cfg.providers,
+		measure:   measure,
+	}
 }
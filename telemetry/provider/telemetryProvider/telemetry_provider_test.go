@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package telemetryProvider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/global"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/label"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/meter/metric"
+	"github.com/cloudwego-contrib/cwgo-pkg/telemetry/semantic"
+)
+
+type mockCounter struct {
+	count int
+}
+
+func (m *mockCounter) Inc(ctx context.Context, labels ...label.CwLabel) error {
+	m.count++
+	return nil
+}
+
+func (m *mockCounter) Add(ctx context.Context, value int, labels ...label.CwLabel) error {
+	m.count += value
+	return nil
+}
+
+type mockProvider struct {
+	measure     metric.Measure
+	shutdownErr error
+	shutdown    bool
+}
+
+func (m *mockProvider) Shutdown(ctx context.Context) error {
+	m.shutdown = true
+	return m.shutdownErr
+}
+
+func (m *mockProvider) Measure() metric.Measure {
+	return m.measure
+}
+
+func TestNewTelemetryProvider(t *testing.T) {
+	httpCounter, rpcCounter := &mockCounter{}, &mockCounter{}
+	errShutdown := errors.New("shutdown failed")
+
+	p1 := &mockProvider{
+		measure:     metric.NewMeasure(metric.WithCounter(semantic.HTTPCounter, httpCounter)),
+		shutdownErr: errShutdown,
+	}
+	p2 := &mockProvider{
+		measure: metric.NewMeasure(metric.WithCounter(semantic.RPCCounter, rpcCounter)),
+	}
+
+	p := NewTelemetryProvider(WithProvider(p1), WithProvider(nil), WithProvider(p2))
+	assert.Len(t, p.(*TelemetryProvider).Providers(), 2)
+
+	// measures of all providers are combined
+	measure := global.GetTracerMeasure()
+	assert.Nil(t, measure.Inc(context.Background(), semantic.HTTPCounter))
+	assert.Nil(t, measure.Add(context.Background(), semantic.RPCCounter, 2))
+	assert.Equal(t, 1, httpCounter.count)
+	assert.Equal(t, 2, rpcCounter.count)
+
+	// all providers are shut down even if one of them fails
+	err := p.Shutdown(context.Background())
+	assert.ErrorIs(t, err, errShutdown)
+	assert.True(t, p1.shutdown)
+	assert.True(t, p2.shutdown)
+}
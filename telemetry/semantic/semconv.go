@@ -25,16 +25,49 @@ type ServiceType string
 
 // Keys for metrics
 const (
-	HTTPCounter = "httpCounter"
-	HTTPLatency = "httpLatency"
+	HTTPCounter      = "httpCounter"
+	HTTPLatency      = "httpLatency"
+	HTTPRequestSize  = "httpRequestSize"
+	HTTPResponseSize = "httpResponseSize"
 
 	RPCCounter = "rpcCounter"
 	RPCLatency = "rpcLatency"
 	RPCRetry   = "rpcRetry"
 
-	Counter = "counter"
-	Latency = "latency"
-	Retry   = "retry"
+	RPCRequestsPerRPC  = "rpcRequestsPerRPC"
+	RPCResponsesPerRPC = "rpcResponsesPerRPC"
+	RPCStreamDuration  = "rpcStreamDuration"
+	RPCRequestSize     = "rpcRequestSize"
+	RPCResponseSize    = "rpcResponseSize"
+	RPCRetryAttempt    = "rpcRetryAttempt"
+	RPCConnectDuration = "rpcConnectDuration"
+	RPCWriteDuration   = "rpcWriteDuration"
+	RPCReadDuration    = "rpcReadDuration"
+	RPCHandleDuration  = "rpcHandleDuration"
+	RPCInFlight        = "rpcInFlight"
+	RPCQueueWait       = "rpcQueueWait"
+	RPCLimitRejection  = "rpcLimitRejection"
+	RPCResolveLatency  = "rpcResolveLatency"
+	RPCInstanceCount   = "rpcInstanceCount"
+	RPCInstancePick    = "rpcInstancePick"
+
+	Counter      = "counter"
+	Latency      = "latency"
+	Retry        = "retry"
+	RequestSize  = "request_size"
+	ResponseSize = "response_size"
+	RetryAttempt = "retry_attempt"
+
+	ConnectDuration = "connect_duration"
+	WriteDuration   = "write_duration"
+	ReadDuration    = "read_duration"
+	HandleDuration  = "handle_duration"
+	InFlight        = "in_flight"
+	QueueWait       = "queue_wait"
+	LimitRejection  = "limit_rejection"
+	ResolveDuration = "resolve_duration"
+	Instances       = "instances"
+	InstancePick    = "instance_pick"
 )
 
 // RPC measure Labels
@@ -42,8 +75,38 @@ const (
 	LabelRPCMethodKey = "rpc_method"
 	LabelRPCCalleeKey = "rpc_service"
 	LabelRPCCallerKey = "caller_rpc_service"
+
+	LabelRPCCallerNamespaceKey   = "caller_namespace"
+	LabelRPCCallerEnvironmentKey = "caller_deployment_environment"
+
 	LabelKeyRetry     = "retry"
 	LabelKeyStatus    = "status"
+	LabelKeyAttempt   = "attempt"
+	LabelKeyBackup    = "backup_request"
+	LabelKeyErrorType = "error_type"
+	LabelKeyLimit     = "limit"
+	LabelKeyResolver  = "resolver"
+	LabelKeyTarget    = "target"
+	LabelKeyBalancer  = "loadbalancer"
+	LabelKeyInstance  = "instance"
+)
+
+// Limit label values
+const (
+	LimitConnection = "connection"
+	LimitQPS        = "qps"
+)
+
+// RPC error types
+const (
+	ErrorTypeNone         = "none"
+	ErrorTypeBiz          = "biz"
+	ErrorTypeTimeout      = "timeout"
+	ErrorTypeCircuitBreak = "circuit_break"
+	ErrorTypeACL          = "acl"
+	ErrorTypeConnection   = "connection"
+	ErrorTypePanic        = "panic"
+	ErrorTypeOther        = "other"
 )
 
 // HTTP measure Labels
@@ -79,6 +142,42 @@ const (
 	// RPCSystemKitexSendSize send_size
 	RPCSystemKitexSendSize = attribute.Key("otelkitex.send_size")
 
+	// RPCRetryAttemptKey number of the attempt of a retried call, 0 for the first one
+	RPCRetryAttemptKey = attribute.Key("rpc.retry.attempt")
+	// RPCBackupRequestKey whether the attempt is a backup request
+	RPCBackupRequestKey = attribute.Key("rpc.retry.backup_request")
+
+	// ErrorTypeKey error.type, classification of the error of the call
+	ErrorTypeKey = attribute.Key("error.type")
+	// RPCBizStatusCodeKey rpc.biz_status_code
+	RPCBizStatusCodeKey = attribute.Key("rpc.biz_status_code")
+	// RPCBizStatusMessageKey rpc.biz_status_message
+	RPCBizStatusMessageKey = attribute.Key("rpc.biz_status_message")
+
+	// RPCPayloadKey rpc.payload, serialized request or response of the call
+	RPCPayloadKey = attribute.Key("rpc.payload")
+	// RPCPayloadTruncatedKey rpc.payload.truncated
+	RPCPayloadTruncatedKey = attribute.Key("rpc.payload.truncated")
+
+	// RPCPayloadCodecKey rpc.kitex.payload_codec, thrift, protobuf or hessian2
+	RPCPayloadCodecKey = attribute.Key("rpc.kitex.payload_codec")
+
+	// RPCGenericKey rpc.kitex.generic, whether the call is made through a kitex generic client or server
+	RPCGenericKey = attribute.Key("rpc.kitex.generic")
+	// RPCGenericTypeKey rpc.kitex.generic.type, representation of the generic payload like json, map, binary or http
+	RPCGenericTypeKey = attribute.Key("rpc.kitex.generic.type")
+
+	// RPCResolverKey rpc.kitex.resolver, name of the resolver of the service discovery
+	RPCResolverKey = attribute.Key("rpc.kitex.resolver")
+	// RPCResolveTargetKey rpc.kitex.resolve.target, description of the resolved target
+	RPCResolveTargetKey = attribute.Key("rpc.kitex.resolve.target")
+	// RPCResolveInstancesKey rpc.kitex.resolve.instances, number of the resolved instances
+	RPCResolveInstancesKey = attribute.Key("rpc.kitex.resolve.instances")
+	// RPCLoadbalancerKey rpc.kitex.loadbalancer, name of the load balancer which picked the instance
+	RPCLoadbalancerKey = attribute.Key("rpc.kitex.loadbalancer")
+	// RPCInstanceAddressKey rpc.kitex.instance.address, address of the instance picked by the load balancer
+	RPCInstanceAddressKey = attribute.Key("rpc.kitex.instance.address")
+
 	// PeerServiceNamespaceKey peer.service.namespace
 	PeerServiceNamespaceKey = attribute.Key("peer.service.namespace")
 	// PeerDeploymentEnvironmentKey peer.deployment.environment
@@ -108,6 +207,17 @@ const (
 	ServerRequestsPerRPC  = "requests_per_rpc"  // measures the number of messages received per RPC. Should be 1 for all non-streaming RPCs
 	ServerResponsesPerRPC = "responses_per_rpc" // measures the number of messages sent per RPC. Should be 1 for all non-streaming RPCs
 	ServerRetry           = "retry"
+	ServerStreamDuration  = "stream_duration"  // measures duration of streaming RPC
+	ServerConnectDuration = "connect_duration" // measures duration of the connection establishment of the client
+	ServerWriteDuration   = "write_duration"   // measures duration of the encoding and writing of the messages
+	ServerReadDuration    = "read_duration"    // measures duration of the waiting and reading of the messages
+	ServerHandleDuration  = "handle_duration"  // measures duration of the server handler
+	ServerInFlight        = "in_flight"        // measures the number of requests handled by the server
+	ServerQueueWait       = "queue_wait"       // measures duration from the decoding of the request to the start of the handler
+	ServerLimitRejection  = "limit_rejection"  // measures the number of requests rejected by the limiter
+	ServerResolveDuration = "resolve_duration" // measures duration of the service discovery lookups
+	ServerInstances       = "instances"        // measures the number of instances resolved for a target
+	ServerInstancePick    = "instance_pick"    // measures the number of instances picked by the load balancer
 )
 
 // Server HTTP meter